@@ -0,0 +1,93 @@
+package mlog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// AsyncHookOptions configures an AsyncHook.
+// Zero values are replaced by sensible defaults.
+type AsyncHookOptions struct {
+	// QueueSize is the maximum number of pending entries. Defaults to 1024.
+	QueueSize int
+	// Workers is the number of goroutines calling the hook. Defaults to 1.
+	// Entries might be processed out of order if there is more than one worker.
+	Workers int
+	// Overflow defines the behaviour if the queue is full.
+	Overflow OverflowPolicy
+}
+
+// AsyncHook calls a hook asynchronously.
+// Entries are passed to worker goroutines via a bounded queue.
+type AsyncHook struct {
+	hook    EntryHook
+	queue   *boundedQueue
+	workers sync.WaitGroup
+
+	closeOnce sync.Once
+}
+
+// NewAsyncHook returns a hook that calls the given hook on separate goroutines.
+// The returned hook must be closed to stop its worker goroutines.
+func NewAsyncHook(hook EntryHook, opts AsyncHookOptions) *AsyncHook {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+
+	h := &AsyncHook{
+		hook:  hook,
+		queue: newBoundedQueue(opts.QueueSize, opts.Overflow),
+	}
+	h.workers.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go h.work()
+	}
+	return h
+}
+
+// Fire enqueues the entry.
+// Depending on the overflow policy, blocks or discards entries if the queue is full.
+// Entries are discarded if the hook was closed.
+func (h *AsyncHook) Fire(entry *Entry) error {
+	h.queue.push(entry)
+	return nil
+}
+
+func (h *AsyncHook) work() {
+	defer h.workers.Done()
+	for {
+		items := h.queue.pop(1)
+		if items == nil {
+			return
+		}
+		if err := h.hook(items[0].(*Entry)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+		}
+		h.queue.done(len(items))
+	}
+}
+
+// Dropped returns the number of entries that were discarded.
+func (h *AsyncHook) Dropped() uint64 {
+	return h.queue.droppedCount()
+}
+
+// Flush blocks until all pending entries were processed, or the context is cancelled.
+func (h *AsyncHook) Flush(ctx context.Context) error {
+	return h.queue.wait(ctx)
+}
+
+// Close processes all pending entries and stops the worker goroutines.
+// Entries fired afterwards are discarded.
+func (h *AsyncHook) Close() error {
+	h.closeOnce.Do(func() {
+		h.queue.close()
+		h.workers.Wait()
+	})
+	return nil
+}
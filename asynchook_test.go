@@ -0,0 +1,126 @@
+package mlog
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncHook(t *testing.T) {
+	var m sync.Mutex
+	var messages []string
+
+	release := make(chan struct{})
+	logger := NewLoggerBuilder("root").
+		WithAsyncHook(AllLevels, func(entry *Entry) error {
+			<-release
+			m.Lock()
+			defer m.Unlock()
+			messages = append(messages, entry.Message)
+			return nil
+		}, AsyncHookOptions{}).
+		WithConsoleFormatter(func(time.Time, Level, string, string) ([]byte, error) {
+			return nil, nil
+		}).
+		Create().(*StdLogger)
+
+	logger.Info("a")
+	logger.Warn("b")
+	close(release)
+
+	assert.NoError(t, logger.Flush(context.Background()))
+	m.Lock()
+	assert.Equal(t, []string{"a", "b"}, messages)
+	m.Unlock()
+
+	assert.NoError(t, logger.Close())
+	logger.Info("c")
+	assert.Equal(t, []string{"a", "b"}, messages)
+}
+
+func TestAsyncHook_Overflow(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest} {
+		var messages []string
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+
+		hook := NewAsyncHook(func(entry *Entry) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			messages = append(messages, entry.Message)
+			return nil
+		}, AsyncHookOptions{
+			QueueSize: 2,
+			Overflow:  policy,
+		})
+
+		assert.NoError(t, hook.Fire(&Entry{Message: "a"}))
+		<-started // worker is blocked processing "a"
+		for _, msg := range []string{"b", "c", "d"} {
+			assert.NoError(t, hook.Fire(&Entry{Message: msg}))
+		}
+		assert.Equal(t, uint64(1), hook.Dropped())
+
+		close(release)
+		assert.NoError(t, hook.Close())
+		if policy == OverflowDropNewest {
+			assert.Equal(t, []string{"a", "b", "c"}, messages)
+		} else {
+			assert.Equal(t, []string{"a", "c", "d"}, messages)
+		}
+	}
+}
+
+func TestAsyncHook_Dropped(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	logger := NewLoggerBuilder("root").
+		WithAsyncHook(AllLevels, func(entry *Entry) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil
+		}, AsyncHookOptions{QueueSize: 1, Overflow: OverflowDropNewest}).
+		WithConsoleFormatter(func(time.Time, Level, string, string) ([]byte, error) {
+			return nil, nil
+		}).
+		Create().(*StdLogger)
+
+	logger.Info("a")
+	<-started // worker is blocked processing "a"
+	logger.Info("b")
+	logger.Info("c")
+	logger.Info("d")
+
+	stats := logger.HookStats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, uint64(2), stats[0].Dropped)
+
+	close(release)
+	assert.NoError(t, logger.Close())
+	assert.Equal(t, uint64(2), logger.HookStats()[0].Calls)
+}
+
+func TestAsyncHook_FlushTimeout(t *testing.T) {
+	release := make(chan struct{})
+	hook := NewAsyncHook(func(entry *Entry) error {
+		<-release
+		return nil
+	}, AsyncHookOptions{})
+	assert.NoError(t, hook.Fire(&Entry{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, hook.Flush(ctx))
+
+	close(release)
+	assert.NoError(t, hook.Close())
+}
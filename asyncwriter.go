@@ -0,0 +1,162 @@
+package mlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncWriterOptions configures an AsyncWriter.
+// Zero values are replaced by sensible defaults.
+type AsyncWriterOptions struct {
+	// BufferSize is the maximum number of pending writes. Defaults to 1024.
+	BufferSize int
+	// BatchSize is the number of bytes after which buffered data is written. Defaults to 32 KiB.
+	BatchSize int
+	// FlushInterval is the maximum duration data is buffered before it is written. Defaults to one second.
+	FlushInterval time.Duration
+	// Overflow defines the behaviour if the buffer is full.
+	Overflow OverflowPolicy
+}
+
+// AsyncWriter decouples writers from the underlying output.
+// Written data is passed to a background goroutine via a ring buffer
+// and written in batches, either when the batch size is reached or the flush interval elapsed.
+type AsyncWriter struct {
+	out   io.Writer
+	opts  AsyncWriterOptions
+	queue *boundedQueue
+
+	flushing  int32 // number of pending Flush calls; accessed atomically
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAsyncWriter returns a writer that writes asynchronously into the given output.
+// The returned writer must be closed to write all pending data and stop its background goroutine.
+func NewAsyncWriter(out io.Writer, opts AsyncWriterOptions) *AsyncWriter {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1024
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 32 * 1024
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+
+	w := &AsyncWriter{
+		out:   out,
+		opts:  opts,
+		queue: newBoundedQueue(opts.BufferSize, opts.Overflow),
+		done:  make(chan struct{}),
+	}
+	go w.work()
+	return w
+}
+
+// Write enqueues a copy of the data.
+// Depending on the overflow policy, blocks or discards data if the buffer is full.
+// Data is discarded if the writer was closed.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	w.queue.push(data)
+	return len(p), nil
+}
+
+func (w *AsyncWriter) work() {
+	defer close(w.done)
+
+	var batch bytes.Buffer
+	batchItems := 0
+
+	timer := time.NewTimer(w.opts.FlushInterval)
+	timer.Stop()
+
+	write := func() {
+		if batch.Len() > 0 {
+			if _, err := w.out.Write(batch.Bytes()); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+			}
+			batch.Reset()
+		}
+		w.queue.done(batchItems)
+		batchItems = 0
+		timer.Stop()
+	}
+
+	for {
+		items := w.queue.tryPop(w.opts.BufferSize)
+		if items != nil {
+			if batchItems == 0 {
+				timer.Reset(w.opts.FlushInterval)
+			}
+			for _, item := range items {
+				batch.Write(item.([]byte))
+			}
+			batchItems += len(items)
+			if batch.Len() >= w.opts.BatchSize {
+				write()
+			}
+			continue
+		}
+
+		if w.queue.isClosed() {
+			write()
+			return
+		}
+		if atomic.LoadInt32(&w.flushing) > 0 {
+			write()
+		}
+		select {
+		case <-w.queue.notify:
+		case <-timer.C:
+			write()
+		}
+	}
+}
+
+// Dropped returns the number of writes that were discarded.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.queue.droppedCount()
+}
+
+// Flush blocks until all pending data was written into the underlying output, or the context is cancelled.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	atomic.AddInt32(&w.flushing, 1)
+	defer atomic.AddInt32(&w.flushing, -1)
+	w.queue.wake()
+	return w.queue.wait(ctx)
+}
+
+// Sync writes all pending data and commits the underlying output to stable storage,
+// if it provides a Sync method (like *os.File).
+func (w *AsyncWriter) Sync() error {
+	if err := w.Flush(context.Background()); err != nil {
+		return err
+	}
+	if syncer, ok := w.out.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close writes all pending data, syncs the underlying output and stops the background goroutine.
+// Does not close the underlying output. Data written afterwards is discarded.
+func (w *AsyncWriter) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		w.queue.close()
+		<-w.done
+		if syncer, ok := w.out.(interface{ Sync() error }); ok {
+			err = syncer.Sync()
+		}
+	})
+	return err
+}
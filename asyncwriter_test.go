@@ -0,0 +1,125 @@
+package mlog
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a thread-safe buffer that records the individual writes and sync calls.
+type syncBuffer struct {
+	m      sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	syncs  int
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.writes++
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.syncs++
+	return nil
+}
+
+func (b *syncBuffer) state() (string, int, int) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.buf.String(), b.writes, b.syncs
+}
+
+func TestAsyncWriter_Batching(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, AsyncWriterOptions{
+		BatchSize:     1024,
+		FlushInterval: time.Hour,
+	})
+
+	for i := 0; i < 10; i++ {
+		_, err := w.Write([]byte("line\n"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Sync())
+
+	data, writes, syncs := out.state()
+	assert.Equal(t, 10*len("line\n"), len(data))
+	assert.Less(t, writes, 10)
+	assert.Equal(t, 1, syncs)
+
+	assert.NoError(t, w.Close())
+	_, _, syncs = out.state()
+	assert.Equal(t, 2, syncs)
+
+	_, err := w.Write([]byte("discarded\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), w.Dropped())
+}
+
+func TestAsyncWriter_FlushInterval(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, AsyncWriterOptions{
+		FlushInterval: 10 * time.Millisecond,
+	})
+	defer w.Close()
+
+	_, err := w.Write([]byte("line\n"))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		data, _, _ := out.state()
+		return data == "line\n"
+	}, time.Second, time.Millisecond)
+}
+
+func TestLoggerBuilder_WithAsyncOutput(t *testing.T) {
+	out := &syncBuffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(out).
+		WithAsyncOutput(AsyncWriterOptions{FlushInterval: time.Hour}).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(msg + "\n"), nil
+		}).
+		Create().(*StdLogger)
+
+	logger.Info("a")
+	logger.Info("b")
+	assert.NoError(t, logger.Flush(context.Background()))
+	data, _, _ := out.state()
+	assert.Equal(t, "a\nb\n", data)
+
+	logger.Info("c")
+	assert.NoError(t, logger.Close())
+	data, _, syncs := out.state()
+	assert.Equal(t, "a\nb\nc\n", data)
+	assert.Equal(t, 1, syncs)
+}
+
+func TestLoggerBuilder_WithAsyncWriter(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewAsyncWriter(out, AsyncWriterOptions{FlushInterval: time.Hour})
+	logger := NewLoggerBuilder("root").
+		WithAsyncWriter(w).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(msg + "\n"), nil
+		}).
+		Create().(*StdLogger)
+
+	logger.Info("a")
+	assert.NoError(t, logger.Close())
+	data, _, _ := out.state()
+	assert.Equal(t, "a\n", data)
+	assert.Equal(t, uint64(0), w.Dropped())
+
+	logger.Info("b")
+	assert.Equal(t, uint64(1), w.Dropped())
+}
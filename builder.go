@@ -26,7 +26,7 @@ type LoggerBuilder struct {
 // NewLoggerBuilder creates a new logger builder.
 func NewLoggerBuilder(name ...string) *LoggerBuilder {
 	return &LoggerBuilder{
-		logger: NewStdLogger(name...),
+		logger: newStdLogger(name...),
 	}
 }
 
@@ -35,15 +35,189 @@ func (f *LoggerBuilder) WithOutput(w io.Writer) *LoggerBuilder {
 	return f
 }
 
+// WithFileOutput writes into a log file that is rotated based on the given options.
+// The file is reopened by StdLogger.Reopen and closed by StdLogger.Close.
+func (f *LoggerBuilder) WithFileOutput(filename string, opts RotatingFileOptions) *LoggerBuilder {
+	w := NewRotatingFileWriter(filename, opts)
+	f.logger.tree.addCloser(w)
+	return f.WithOutput(w)
+}
+
+// AddSink adds an additional output with its own minimum level and formatter.
+// Sinks only receive messages that are enabled by the logger's level,
+// so the sink level has no effect if it is less severe than the logger's level.
+// Like hooks, sinks are reported via StdLogger.HookStats and write errors are passed to the HookErrorHandler.
+// They are independent of the primary output configured via WithOutput.
+// If the formatter is nil, a console formatter with default options is used.
+//
+// To write info messages to the console and debug messages to a JSON file,
+// lower the logger's level to the most verbose sink and disable the primary output:
+//
+//	logger := mlog.NewLoggerBuilder("app").
+//		WithOutput(io.Discard).
+//		WithLevel(mlog.DebugLevel).
+//		AddSink(os.Stderr, mlog.InfoLevel, nil).
+//		AddSink(file, mlog.DebugLevel, mlog.JSONFormatter).
+//		Create()
+func (f *LoggerBuilder) AddSink(w io.Writer, level Level, formatter EntryFormatter) *LoggerBuilder {
+	if formatter == nil {
+		formatter = NewConsoleFormatter(ConsoleOptions{})
+	}
+	s := &sink{
+		out:       w,
+		level:     level,
+		formatter: formatter,
+	}
+	f.logger.AddEntryHook(s.levels(), s.write)
+	return f
+}
+
+// WithAsyncOutput decouples logging from I/O by wrapping the current output into an AsyncWriter.
+// Must be called after the output was configured.
+// Pending data is written by StdLogger.Flush and StdLogger.Close.
+// Use WithAsyncWriter to access the writer, e.g. for AsyncWriter.Dropped.
+func (f *LoggerBuilder) WithAsyncOutput(opts AsyncWriterOptions) *LoggerBuilder {
+	return f.WithAsyncWriter(NewAsyncWriter(f.logger.rawLogger().Out, opts))
+}
+
+// WithAsyncWriter writes into the given asynchronous writer.
+// Pending data is written by StdLogger.Flush and StdLogger.Close.
+func (f *LoggerBuilder) WithAsyncWriter(w *AsyncWriter) *LoggerBuilder {
+	f.logger.tree.addCloser(w)
+	return f.WithOutput(w)
+}
+
 func (f *LoggerBuilder) WithLevel(level Level) *LoggerBuilder {
 	f.logger.SetLevel(level)
 	return f
 }
 
+// WithLevelRegistry uses the given registry to configure levels based on logger names.
+// Allows sharing a registry between multiple logger trees.
+func (f *LoggerBuilder) WithLevelRegistry(registry *LevelRegistry) *LoggerBuilder {
+	f.logger.tree.levels = registry
+	return f
+}
+
+// WithNameLevel sets the level of all loggers with the given name or name prefix.
+// See LevelRegistry.
+func (f *LoggerBuilder) WithNameLevel(name string, level Level) *LoggerBuilder {
+	f.logger.tree.levels.SetLevel(name, level)
+	return f
+}
+
+// WithVerbosity sets the verbosity of all loggers. See Logger.V.
+func (f *LoggerBuilder) WithVerbosity(v int) *LoggerBuilder {
+	return f.WithNameVerbosity("", v)
+}
+
+// WithNameVerbosity sets the verbosity of all loggers with the given name or name prefix.
+// See VerbosityRegistry.
+func (f *LoggerBuilder) WithNameVerbosity(name string, v int) *LoggerBuilder {
+	f.logger.tree.verbosity.SetVerbosity(name, v)
+	return f
+}
+
+// WithVerbosityRegistry uses the given registry to configure verbosities based on logger names.
+// Allows sharing a registry between multiple logger trees.
+func (f *LoggerBuilder) WithVerbosityRegistry(registry *VerbosityRegistry) *LoggerBuilder {
+	f.logger.tree.verbosity = registry
+	return f
+}
+
+// WithSampler samples messages of all loggers with the given name or name prefix.
+// The sampler of the most specific name takes precedence. The empty name matches all loggers.
+// The sampler is closed by StdLogger.Close. Outputs and hooks should be configured first,
+// so that the final summary is written before they are closed.
+func (f *LoggerBuilder) WithSampler(name string, sampler *Sampler) *LoggerBuilder {
+	if f.logger.tree.samplers == nil {
+		f.logger.tree.samplers = make(map[string]*Sampler)
+	}
+	f.logger.tree.samplers[name] = sampler
+	f.logger.tree.addCloser(sampler)
+	return f
+}
+
+// WithRateLimiter limits the number of messages of all loggers with the given name or name prefix.
+// The rate limiter of the most specific name takes precedence. The empty name matches all loggers.
+// The rate limiter is closed by StdLogger.Close. Outputs and hooks should be configured first,
+// so that pending numbers of dropped messages are written before they are closed.
+func (f *LoggerBuilder) WithRateLimiter(name string, limiter *RateLimiter) *LoggerBuilder {
+	if f.logger.tree.rateLimiters == nil {
+		f.logger.tree.rateLimiters = make(map[string]*RateLimiter)
+	}
+	f.logger.tree.rateLimiters[name] = limiter
+	f.logger.tree.addCloser(limiter)
+	return f
+}
+
+// WithDeduplication collapses messages with identical level, logger name and message within the given window.
+// The first message is logged immediately. If it was repeated within the window,
+// it is logged once more when the window ends, with the number of repetitions in the "repeated" field.
+// Pending repetitions are logged by StdLogger.Close. Outputs and hooks should be configured first.
+// Deduplication is disabled if the window is not positive.
+func (f *LoggerBuilder) WithDeduplication(window time.Duration) *LoggerBuilder {
+	if window <= 0 {
+		f.logger.tree.dedup = nil
+		return f
+	}
+	f.logger.tree.dedup = newDeduplicator(window)
+	f.logger.tree.addCloser(f.logger.tree.dedup)
+	return f
+}
+
+// WithInheritLevel lets sub-loggers follow the level of their parent, until their level is explicitly changed.
+// By default, sub-loggers copy the parent's level on creation.
+func (f *LoggerBuilder) WithInheritLevel() *LoggerBuilder {
+	f.logger.tree.inheritLevel = true
+	return f
+}
+
+// WithCaller reports the call site of log statements, which is available to formatters via Entry.Caller.
+// It is printed by entry formatters like NewConsoleFormatter, but not by formatters passed to WithConsoleFormatter:
+//
+//	builder.WithEntryFormatter(mlog.NewConsoleFormatter(mlog.ConsoleOptions{})).WithCaller(0)
+//
+// Frames within this package are skipped automatically.
+// Skip is the number of additional frames to skip, for example within custom logging wrappers.
+func (f *LoggerBuilder) WithCaller(skip int) *LoggerBuilder {
+	f.logger.tree.reportCaller = true
+	f.logger.tree.callerSkip = skip
+	return f
+}
+
+// WithStackTrace attaches a stack trace to messages with the given levels, which defaults to ErrorLevel.
+// If an error argument or the "error" field provides a stack trace, like errors of github.com/pkg/errors,
+// the stack trace of the error's origin is used. Otherwise, the stack of the current goroutine is captured.
+// The stack trace is available to formatters via Entry.StackTrace.
+// Like the call site, it is printed by entry formatters like NewConsoleFormatter, see WithCaller.
+func (f *LoggerBuilder) WithStackTrace(levels ...Level) *LoggerBuilder {
+	if len(levels) == 0 {
+		levels = []Level{ErrorLevel}
+	}
+	f.logger.tree.stackTraceLevels = levels
+	return f
+}
+
+// WithExitFunc replaces the function that terminates the program on fatal messages, which defaults to os.Exit.
+// Allows tests to intercept Logger.Fatal. Fatal returns if the exit function returns.
+// The logger tree is closed before the exit function is called.
+func (f *LoggerBuilder) WithExitFunc(exit func(code int)) *LoggerBuilder {
+	f.logger.tree.exitFunc = exit
+	return f
+}
+
 // The Formatter converts a log message into a string suitable for console output.
+// Fields are appended to the message. Use an EntryFormatter to access them directly.
 type Formatter func(timestamp time.Time, level Level, name string, msg string) ([]byte, error)
 
+// WithConsoleFormatter sets a formatter that only receives the message, with fields appended.
+// Entry formatters like JSONFormatter, LogfmtFormatter and NewConsoleFormatter are set via WithEntryFormatter.
 func (f *LoggerBuilder) WithConsoleFormatter(formatter Formatter) *LoggerBuilder {
+	return f.WithEntryFormatter(formatter.EntryFormatter())
+}
+
+func (f *LoggerBuilder) WithEntryFormatter(formatter EntryFormatter) *LoggerBuilder {
 	f.logger.rawLogger().SetFormatter(&logrusFormatterAdapter{
 		formatter: formatter,
 	})
@@ -51,12 +225,36 @@ func (f *LoggerBuilder) WithConsoleFormatter(formatter Formatter) *LoggerBuilder
 }
 
 // Hook is called for outgoing log messages.
+// Fields are appended to the message. Use an EntryHook to access them directly.
 // Note: hooks are not called asynchronously and should therefore be non-blocking.
+// Use LoggerBuilder.WithAsyncHook for blocking hooks.
 type Hook func(timestamp time.Time, level Level, name string, msg string) error
 
 func (f *LoggerBuilder) WithHook(levels []Level, hook Hook) *LoggerBuilder {
-	adapter := newLogrusHookAdapter(levels, hook)
-	f.logger.rawLogger().AddHook(adapter)
+	return f.WithEntryHook(levels, hook.EntryHook())
+}
+
+func (f *LoggerBuilder) WithEntryHook(levels []Level, hook EntryHook) *LoggerBuilder {
+	f.logger.AddEntryHook(levels, hook)
+	return f
+}
+
+// WithAsyncHook adds a hook that is called asynchronously via a bounded queue.
+// Pending entries are processed by StdLogger.Flush and StdLogger.Close.
+// The number of discarded entries is reported via StdLogger.HookStats.
+func (f *LoggerBuilder) WithAsyncHook(levels []Level, hook EntryHook, opts AsyncHookOptions) *LoggerBuilder {
+	state := f.logger.tree.newHookState()
+	asyncHook := NewAsyncHook(state.wrap(hook), opts)
+	state.setAsync(asyncHook)
+	f.logger.tree.addCloser(asyncHook)
+	f.logger.rawLogger().AddHook(newLogrusHookAdapter(levels, asyncHook.Fire))
+	return f
+}
+
+// WithHookErrorHandler configures how errors returned by hooks are handled.
+// By default, errors are printed to stderr.
+func (f *LoggerBuilder) WithHookErrorHandler(handler HookErrorHandler) *LoggerBuilder {
+	f.logger.tree.hookErrorHandler = handler
 	return f
 }
 
@@ -65,20 +263,21 @@ func (f *LoggerBuilder) Create() Logger {
 }
 
 type logrusFormatterAdapter struct {
-	formatter Formatter
+	formatter EntryFormatter
 }
 
 func (a *logrusFormatterAdapter) Format(entry *logrus.Entry) ([]byte, error) {
-	name := entry.Data["name"].(string)
-	return a.formatter(entry.Time, Level(entry.Level), name, entry.Message)
+	e := newEntry(entry)
+	e.output = entry.Logger.Out
+	return a.formatter(e)
 }
 
 type logrusHookAdapter struct {
 	levels []logrus.Level
-	hook   Hook
+	hook   EntryHook
 }
 
-func newLogrusHookAdapter(levels []Level, hook Hook) *logrusHookAdapter {
+func newLogrusHookAdapter(levels []Level, hook EntryHook) *logrusHookAdapter {
 	logrusLevels := make([]logrus.Level, len(levels))
 	for i, lvl := range levels {
 		logrusLevels[i] = logrus.Level(lvl)
@@ -94,6 +293,5 @@ func (a *logrusHookAdapter) Levels() []logrus.Level {
 }
 
 func (a *logrusHookAdapter) Fire(entry *logrus.Entry) error {
-	name := entry.Data["name"].(string)
-	return a.hook(entry.Time, Level(entry.Level), name, entry.Message)
+	return a.hook(newEntry(entry))
 }
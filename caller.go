@@ -0,0 +1,75 @@
+package mlog
+
+import (
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// callerKey is the key of the logrus entry field containing the call site.
+// It is not exposed as a user-defined field.
+const callerKey = "mlog.caller"
+
+// sourceDir is the directory containing the source files of this package.
+var sourceDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return path.Dir(file)
+}()
+
+// wrapperPackages are packages that forward messages to loggers of this package.
+var wrapperPackages = []string{
+	"log.",      // RedirectStdLogger
+	"log/slog.", // SlogHandler
+}
+
+// callerFrame returns the call site of a log statement.
+// Frames of this package and its sub-packages, as well as of known wrapper packages, are skipped.
+// Afterwards, another skip frames are skipped.
+func callerFrame(skip int) *runtime.Frame {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip runtime.Callers and callerFrame
+	frames := runtime.CallersFrames(pcs[:n])
+	if frame, ok := skipWrapperFrames(frames, skip); ok {
+		return &frame
+	}
+	return nil
+}
+
+// skipWrapperFrames returns the first frame after all wrapper frames and another skip frames.
+func skipWrapperFrames(frames *runtime.Frames, skip int) (runtime.Frame, bool) {
+	inWrapper := true
+	for {
+		frame, more := frames.Next()
+		if inWrapper && !isWrapperFrame(frame) {
+			inWrapper = false
+		}
+		if !inWrapper {
+			if skip <= 0 {
+				return frame, true
+			}
+			skip--
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func isWrapperFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.File, sourceDir+"/") && !strings.HasSuffix(frame.File, "_test.go") {
+		return true
+	}
+	for _, pkg := range wrapperPackages {
+		if strings.HasPrefix(frame.Function, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortCaller returns the call site in the form "pkg/file.go:123".
+func shortCaller(frame *runtime.Frame) string {
+	dir, file := path.Split(frame.File)
+	return path.Join(path.Base(dir), file) + ":" + strconv.Itoa(frame.Line)
+}
@@ -0,0 +1,92 @@
+package mlog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestStdLogger_Caller(t *testing.T) {
+	logger, _, entries := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithCaller(0)
+	})
+
+	line := currentLine()
+	logger.Info("msg")
+	logger.With("k", "v").Warnf("msg")
+	logger.New("sub").ErrorCtx(context.Background(), "msg")
+
+	assert.Len(t, *entries, 3)
+	for i, entry := range *entries {
+		assert.NotNil(t, entry.Caller)
+		assert.Equal(t, line+1+i, entry.Caller.Line)
+		assert.Equal(t, "caller_test.go", path.Base(entry.Caller.File))
+		assert.NotContains(t, entry.Fields, callerKey)
+	}
+}
+
+func TestStdLogger_CallerSkip(t *testing.T) {
+	logger, _, entries := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithCaller(1)
+	})
+	logWrapper := func(msg string) {
+		logger.Info(msg)
+	}
+
+	line := currentLine()
+	logWrapper("msg")
+
+	assert.Len(t, *entries, 1)
+	assert.Equal(t, line+1, (*entries)[0].Caller.Line)
+}
+
+func TestStdLogger_CallerDisabled(t *testing.T) {
+	logger, _, entries := newEntryTestLogger(nil)
+
+	logger.Info("msg")
+	assert.Len(t, *entries, 1)
+	assert.Nil(t, (*entries)[0].Caller)
+}
+
+func TestConsoleFormatter_Caller(t *testing.T) {
+	formatter := NewConsoleFormatter(ConsoleOptions{
+		NameWidth: -1,
+		Color:     ColorNever,
+	})
+	data, err := formatter(&Entry{
+		Time:    time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:   InfoLevel,
+		Name:    "root",
+		Message: "msg",
+		Caller: &runtime.Frame{
+			File: "/home/user/project/pkg/file.go",
+			Line: 123,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "15.05.2021 12:30:00 INFO root pkg/file.go:123 ▶ msg\n", string(data))
+}
+
+func TestConsoleFormatter_CallerViaBuilder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithEntryFormatter(NewConsoleFormatter(ConsoleOptions{})).
+		WithCaller(0).
+		Create()
+
+	line := currentLine()
+	logger.Info("msg")
+	assert.Contains(t, buf.String(), fmt.Sprintf("caller_test.go:%d", line+1))
+}
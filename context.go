@@ -0,0 +1,117 @@
+package mlog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type loggerContextKey struct{}
+
+// NewContext returns a copy of the context that carries the logger.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger stored in the context.
+// Returns the default logger if the context does not carry a logger.
+// Fields of registered context values are added to the returned logger.
+func FromContext(ctx context.Context) Logger {
+	logger, ok := ctx.Value(loggerContextKey{}).(Logger)
+	if !ok {
+		logger = DefaultLogger()
+	}
+	return logger.WithContext(ctx)
+}
+
+var defaultLogger atomic.Value // loggerHolder
+
+// loggerHolder allows storing different Logger implementations within an atomic.Value.
+type loggerHolder struct {
+	logger Logger
+}
+
+// DefaultLogger returns the logger used by FromContext if a context does not carry a logger.
+// Defaults to NOPLogger.
+func DefaultLogger() Logger {
+	holder, ok := defaultLogger.Load().(loggerHolder)
+	if !ok {
+		return NOPLogger
+	}
+	return holder.logger
+}
+
+// SetDefaultLogger changes the logger used by FromContext if a context does not carry a logger.
+func SetDefaultLogger(logger Logger) {
+	defaultLogger.Store(loggerHolder{logger})
+}
+
+// ContextExtractor returns fields for values stored within a context.
+type ContextExtractor func(ctx context.Context) Fields
+
+var contextExtractors struct {
+	m          sync.RWMutex
+	extractors []*ContextExtractor
+}
+
+// RegisterContextExtractor registers a function that extracts fields from contexts.
+// The fields are added to log messages of context-aware log methods, like InfoCtx.
+// Returns a function that removes the extractor again.
+func RegisterContextExtractor(extractor ContextExtractor) (unregister func()) {
+	contextExtractors.m.Lock()
+	defer contextExtractors.m.Unlock()
+
+	registered := &extractor
+	contextExtractors.extractors = append(contextExtractors.extractors, registered)
+	return func() {
+		unregisterContextExtractor(registered)
+	}
+}
+
+func unregisterContextExtractor(extractor *ContextExtractor) {
+	contextExtractors.m.Lock()
+	defer contextExtractors.m.Unlock()
+
+	extractors := contextExtractors.extractors
+	for i, e := range extractors {
+		if e == extractor {
+			// copy, as ContextFields might still iterate over the old slice
+			contextExtractors.extractors = append(extractors[:i:i], extractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// RegisterContextKey adds the context value with the given key as field to log messages of context-aware log methods.
+// The value is omitted if the context does not contain the key.
+// Returns a function that removes the key again.
+func RegisterContextKey(key interface{}, field string) (unregister func()) {
+	return RegisterContextExtractor(func(ctx context.Context) Fields {
+		val := ctx.Value(key)
+		if val == nil {
+			return nil
+		}
+		return Fields{field: val}
+	})
+}
+
+// ContextFields returns the fields of all registered context values.
+func ContextFields(ctx context.Context) Fields {
+	contextExtractors.m.RLock()
+	defer contextExtractors.m.RUnlock()
+
+	var fields Fields
+	for _, extract := range contextExtractors.extractors {
+		extracted := (*extract)(ctx)
+		if len(extracted) == 0 {
+			continue
+		}
+		if fields == nil {
+			fields = make(Fields, len(extracted))
+		}
+		for k, v := range extracted {
+			fields[k] = v
+		}
+	}
+	return fields
+}
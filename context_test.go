@@ -0,0 +1,79 @@
+package mlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testContextKey string
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, NOPLogger, FromContext(context.Background()))
+
+	mem := NewMemLogger()
+	SetDefaultLogger(mem)
+	defer SetDefaultLogger(NOPLogger)
+	FromContext(context.Background()).Info("default")
+	mem.AssertAllMessages(t, InfoLevel, "default")
+
+	logger, buf := newTestLogger("root")
+	ctx := NewContext(context.Background(), logger)
+	FromContext(ctx).Warn("msg")
+	assert.Equal(t, "warn root msg\n", buf.String())
+}
+
+func TestContextFields(t *testing.T) {
+	key := testContextKey("request")
+	t.Cleanup(RegisterContextKey(key, "request_id"))
+
+	assert.Empty(t, ContextFields(context.Background()))
+
+	ctx := context.WithValue(context.Background(), key, 42)
+	assert.Equal(t, Fields{"request_id": 42}, ContextFields(ctx))
+
+	logger, buf := newTestLogger("root")
+	logger.InfoCtx(ctx, "msg")
+	logger.WithContext(ctx).Warnf("msg %d", 1)
+	logger.DebugCtx(ctx, "disabled")
+	logger.Info("msg")
+	assert.Equal(t, "info root msg request_id=42\n"+
+		"warn root msg 1 request_id=42\n"+
+		"info root msg\n", buf.String())
+
+	mem := NewMemLogger()
+	mem.ErrorCtx(ctx, "msg")
+	assert.Equal(t, []Fields{{"request_id": 42}}, mem.Fields(ErrorLevel))
+	assert.Equal(t, ctx, mem.Entries(ErrorLevel)[0].Context)
+}
+
+func TestStdLogger_ContextInEntry(t *testing.T) {
+	logger, _, captured := newEntryTestLogger(nil)
+
+	ctx := context.WithValue(context.Background(), testContextKey("other"), "value")
+	logger.InfoCtx(ctx, "msg")
+	logger.WithContext(ctx).With("k", "v").Info("msg")
+	logger.Info("msg")
+
+	entries := *captured
+	assert.Len(t, entries, 3)
+	assert.Equal(t, ctx, entries[0].Context)
+	assert.Equal(t, ctx, entries[1].Context)
+	assert.Nil(t, entries[2].Context)
+}
+
+func TestRegisterContextExtractor_Unregister(t *testing.T) {
+	key := testContextKey("unregister")
+	ctx := context.WithValue(context.Background(), key, 1)
+
+	unregister := RegisterContextKey(key, "first")
+	unregisterOther := RegisterContextKey(key, "second")
+	assert.Equal(t, Fields{"first": 1, "second": 1}, ContextFields(ctx))
+
+	unregister()
+	assert.Equal(t, Fields{"second": 1}, ContextFields(ctx))
+	unregister() // no-op
+	unregisterOther()
+	assert.Empty(t, ContextFields(ctx))
+}
@@ -0,0 +1,154 @@
+package mlog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelDefinition describes a custom log level.
+type LevelDefinition struct {
+	// Name of the level, as returned by Level.String and accepted by ParseLevel.
+	Name string
+	// Severity orders the level relative to all other levels. Higher values are more severe.
+	// See Level.Severity for the severities of the predefined levels.
+	Severity int
+	// Color is the ANSI color code used by console formatters, like "32" for green or "1;31" for bold red.
+	// Defaults to the color of the closest predefined level with a lower or equal severity.
+	Color string
+}
+
+// levelTable contains all custom levels. Never modified after creation.
+type levelTable struct {
+	defs   map[Level]LevelDefinition
+	byName map[string]Level
+	levels []Level // all levels, ordered from the most to the least severe one
+}
+
+var customLevels = struct {
+	m     sync.Mutex   // for registration
+	table atomic.Value // *levelTable
+}{}
+
+func loadLevelTable() *levelTable {
+	table, ok := customLevels.table.Load().(*levelTable)
+	if !ok { // no custom levels
+		return &levelTable{
+			levels: []Level{PanicLevel, FatalLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel, TraceLevel},
+		}
+	}
+	return table
+}
+
+// Levels returns all logging levels, ordered from the most to the least severe one.
+// Includes custom levels. Unlike AllLevels, it can be called concurrently with RegisterLevel.
+func Levels() []Level {
+	levels := loadLevelTable().levels
+	return append([]Level(nil), levels...)
+}
+
+// RegisterLevel adds a custom log level and returns it.
+// Custom levels should be registered during initialization, before any loggers are created,
+// as hooks and sinks do not receive messages of levels that are registered afterwards.
+// The level is added to Levels and AllLevels. AllLevels must not be accessed concurrently.
+func RegisterLevel(def LevelDefinition) (Level, error) {
+	def.Name = strings.ToLower(def.Name)
+	if def.Name == "" {
+		return 0, errors.New("missing level name")
+	}
+
+	customLevels.m.Lock()
+	defer customLevels.m.Unlock()
+
+	if _, err := ParseLevel(def.Name); err == nil {
+		return 0, fmt.Errorf("level %q already exists", def.Name)
+	}
+
+	old := loadLevelTable()
+	table := &levelTable{
+		defs:   make(map[Level]LevelDefinition, len(old.defs)+1),
+		byName: make(map[string]Level, len(old.byName)+1),
+	}
+	for lvl, d := range old.defs {
+		table.defs[lvl] = d
+	}
+	for name, lvl := range old.byName {
+		table.byName[name] = lvl
+	}
+
+	level := TraceLevel + 1 + Level(len(old.defs))
+	table.defs[level] = def
+	table.byName[def.Name] = level
+	table.levels = append(append([]Level(nil), old.levels...), level)
+	sort.SliceStable(table.levels, func(i, j int) bool {
+		return table.levels[j].severity(table) < table.levels[i].severity(table)
+	})
+	customLevels.table.Store(table)
+
+	AllLevels = append([]Level(nil), table.levels...)
+	return level, nil
+}
+
+// MustRegisterLevel is like RegisterLevel, but panics on error.
+// Intended for initializing global level variables.
+func MustRegisterLevel(def LevelDefinition) Level {
+	level, err := RegisterLevel(def)
+	if err != nil {
+		panic(err)
+	}
+	return level
+}
+
+// isBuiltin returns true for predefined levels.
+func (l Level) isBuiltin() bool {
+	return l <= TraceLevel
+}
+
+// definition returns the definition of a custom level.
+func (l Level) definition() (LevelDefinition, bool) {
+	def, ok := loadLevelTable().defs[l]
+	return def, ok
+}
+
+// builtinSeverities contains the severities of all predefined levels, indexed by level.
+var builtinSeverities = [...]int{
+	PanicLevel: 70,
+	FatalLevel: 60,
+	ErrorLevel: 50,
+	WarnLevel:  40,
+	InfoLevel:  30,
+	DebugLevel: 20,
+	TraceLevel: 10,
+}
+
+// Severity returns the level's severity. Higher values are more severe.
+// Predefined levels have severities from 10 (TraceLevel) to 70 (PanicLevel) in steps of 10.
+// Unknown levels have a severity of 0.
+func (l Level) Severity() int {
+	return l.severity(loadLevelTable())
+}
+
+func (l Level) severity(table *levelTable) int {
+	if l.isBuiltin() {
+		return builtinSeverities[l]
+	}
+	return table.defs[l].Severity
+}
+
+// closestBuiltin returns the most severe predefined level with a lower or equal severity.
+// Returns TraceLevel if there is none.
+func (l Level) closestBuiltin() Level {
+	if l.isBuiltin() {
+		return l
+	}
+	severity := l.Severity()
+	for lvl := PanicLevel; lvl < TraceLevel; lvl++ {
+		if builtinSeverities[lvl] <= severity {
+			return lvl
+		}
+	}
+	return TraceLevel
+}
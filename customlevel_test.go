@@ -0,0 +1,88 @@
+package mlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Custom levels are registered globally and therefore also visible within other tests.
+var (
+	testNoticeLevel   = MustRegisterLevel(LevelDefinition{Name: "Notice", Severity: 35, Color: "32"})
+	testCriticalLevel = MustRegisterLevel(LevelDefinition{Name: "critical", Severity: 55})
+	testVerboseLevel  = MustRegisterLevel(LevelDefinition{Name: "v1", Severity: 25})
+)
+
+func TestRegisterLevel(t *testing.T) {
+	_, err := RegisterLevel(LevelDefinition{Name: "NOTICE"})
+	assert.Error(t, err)
+	_, err = RegisterLevel(LevelDefinition{Name: "error"})
+	assert.Error(t, err)
+	_, err = RegisterLevel(LevelDefinition{})
+	assert.Error(t, err)
+
+	assert.Equal(t, "notice", testNoticeLevel.String())
+	assert.Equal(t, 35, testNoticeLevel.Severity())
+	assert.Equal(t, 30, InfoLevel.Severity())
+
+	lvl, err := ParseLevel("NOTICE")
+	assert.NoError(t, err)
+	assert.Equal(t, testNoticeLevel, lvl)
+
+	assert.Equal(t, []Level{
+		PanicLevel, FatalLevel, testCriticalLevel, ErrorLevel, WarnLevel,
+		testNoticeLevel, InfoLevel, testVerboseLevel, DebugLevel, TraceLevel,
+	}, AllLevels)
+	assert.Equal(t, AllLevels, Levels())
+}
+
+func TestCustomLevel_LessSevereThan(t *testing.T) {
+	assert.True(t, InfoLevel.LessSevereThan(testNoticeLevel))
+	assert.True(t, testNoticeLevel.LessSevereThan(WarnLevel))
+	assert.True(t, testNoticeLevel.LessSevereThan(testCriticalLevel))
+	assert.True(t, testCriticalLevel.LessSevereThan(FatalLevel))
+	assert.False(t, testNoticeLevel.LessSevereThan(testNoticeLevel))
+	assert.False(t, testNoticeLevel.LessSevereThan(testVerboseLevel))
+}
+
+func TestStdLogger_CustomLevel(t *testing.T) {
+	var hooked []Level
+	buf := &bytes.Buffer{}
+	sink := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(level.String() + " " + msg + "\n"), nil
+		}).
+		WithEntryHook(AllLevels, func(entry *Entry) error {
+			hooked = append(hooked, entry.Level)
+			return nil
+		}).
+		AddSink(sink, testNoticeLevel, func(entry *Entry) ([]byte, error) {
+			return []byte(entry.Level.String() + "\n"), nil
+		}).
+		WithLevel(testNoticeLevel).
+		Create()
+
+	logger.Log(testVerboseLevel, "disabled")
+	logger.Info("disabled")
+	logger.Log(testNoticeLevel, "notice")
+	logger.Logf(testCriticalLevel, "critical %d", 1)
+
+	assert.Equal(t, "notice notice\ncritical critical 1\n", buf.String())
+	assert.Equal(t, "notice\ncritical\n", sink.String())
+	assert.Equal(t, []Level{testNoticeLevel, testCriticalLevel}, hooked)
+
+	logger.SetLevel(testVerboseLevel)
+	assert.True(t, logger.IsLevelEnabled(InfoLevel))
+	assert.True(t, logger.IsLevelEnabled(testVerboseLevel))
+	assert.False(t, logger.IsLevelEnabled(DebugLevel))
+}
+
+func TestCustomLevel_Color(t *testing.T) {
+	assert.Equal(t, "32", levelColor(testNoticeLevel))
+	assert.Equal(t, red, levelColor(testCriticalLevel))
+	assert.Equal(t, gray, levelColor(testVerboseLevel))
+}
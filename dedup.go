@@ -0,0 +1,142 @@
+package mlog
+
+import (
+	"sync"
+	"time"
+)
+
+// deduplicator collapses identical messages within a time window.
+// The first message is logged immediately. Repetitions within the window are counted
+// and logged as a single message with a "repeated" field once the window ends.
+type deduplicator struct {
+	window time.Duration
+	now    func() time.Time
+
+	m       sync.Mutex
+	entries map[dedupKey]*dedupEntry
+	closed  bool // messages are no longer deduplicated
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// dedupKey identifies identical messages.
+type dedupKey struct {
+	level Level
+	name  string
+	msg   string
+}
+
+type dedupEntry struct {
+	start    time.Time
+	repeated int
+	logger   *StdLogger // that logged the last repetition
+}
+
+func newDeduplicator(window time.Duration) *deduplicator {
+	d := &deduplicator{
+		window:  window,
+		now:     time.Now,
+		entries: make(map[dedupKey]*dedupEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go d.work()
+	return d
+}
+
+// allow returns true if the message should be logged.
+func (d *deduplicator) allow(logger *StdLogger, level Level, msg string) bool {
+	key := dedupKey{level: level, name: logger.name, msg: msg}
+	now := d.now()
+
+	d.m.Lock()
+	if d.closed {
+		d.m.Unlock()
+		return true
+	}
+	entry, ok := d.entries[key]
+	if ok && now.Sub(entry.start) < d.window {
+		entry.repeated++
+		entry.logger = logger
+		d.m.Unlock()
+		return false
+	}
+	d.entries[key] = &dedupEntry{
+		start:  now,
+		logger: logger,
+	}
+	d.m.Unlock()
+
+	if ok { // window expired, but the repetitions were not logged yet
+		d.logRepetitions(key, entry)
+	}
+	return true
+}
+
+// work periodically logs the repetitions of expired windows until the deduplicator is closed.
+func (d *deduplicator) work() {
+	defer close(d.done)
+	ticker := time.NewTicker(dedupTick(d.window))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(false)
+		case <-d.stop:
+			d.flush(true)
+			return
+		}
+	}
+}
+
+// minDedupTick limits how often expired windows are checked.
+const minDedupTick = time.Millisecond
+
+// dedupTick returns the interval at which expired windows are checked.
+func dedupTick(window time.Duration) time.Duration {
+	return max(window/4, minDedupTick)
+}
+
+// flush logs the repetitions of all expired windows, or of all windows if all is true.
+func (d *deduplicator) flush(all bool) {
+	now := d.now()
+	expired := make(map[dedupKey]*dedupEntry)
+
+	d.m.Lock()
+	for key, entry := range d.entries {
+		if all || now.Sub(entry.start) >= d.window {
+			expired[key] = entry
+			delete(d.entries, key)
+		}
+	}
+	d.m.Unlock()
+
+	for key, entry := range expired {
+		d.logRepetitions(key, entry)
+	}
+}
+
+// logRepetitions logs the message once more, with the number of repetitions as field.
+func (d *deduplicator) logRepetitions(key dedupKey, entry *dedupEntry) {
+	if entry.repeated == 0 {
+		return
+	}
+	entry.logger.withFields(Fields{"repeated": entry.repeated}, entry.logger.rawEntry.Context).
+		write(key.level, nil, key.msg)
+}
+
+// Close stops the deduplicator and logs all pending repetitions.
+// Messages logged afterwards are no longer deduplicated.
+func (d *deduplicator) Close() error {
+	d.closeOnce.Do(func() {
+		d.m.Lock()
+		d.closed = true
+		d.m.Unlock()
+		close(d.stop)
+	})
+	<-d.done
+	return nil
+}
@@ -0,0 +1,76 @@
+package mlog
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogger_Deduplication(t *testing.T) {
+	logger, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithDeduplication(time.Hour)
+	})
+	tree := logger.(*StdLogger).tree
+
+	now := time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC)
+	tree.dedup.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		logger.Error("connection refused")
+		logger.Warn("connection refused")
+	}
+	logger.New("sub").Error("connection refused")
+	assert.Equal(t, "error root connection refused\n"+
+		"warn root connection refused\n"+
+		"error root.sub connection refused\n", buf.String())
+
+	buf.Reset()
+	now = now.Add(time.Hour)
+	tree.dedup.flush(false)
+	assert.Equal(t, "error root connection refused repeated=2\n"+
+		"warn root connection refused repeated=2\n", sortedLines(buf.String()))
+
+	buf.Reset()
+	logger.Error("connection refused")
+	logger.Error("connection refused")
+	now = now.Add(2 * time.Hour)
+	logger.Error("connection refused")
+	assert.Equal(t, "error root connection refused\n"+
+		"error root connection refused repeated=1\n"+
+		"error root connection refused\n", buf.String())
+
+	buf.Reset()
+	logger.Error("connection refused")
+	assert.NoError(t, logger.(*StdLogger).Close())
+	assert.Equal(t, "error root connection refused repeated=1\n", buf.String())
+
+	// not deduplicated after closing
+	buf.Reset()
+	logger.Error("connection refused")
+	logger.Error("connection refused")
+	assert.Equal(t, "error root connection refused\nerror root connection refused\n", buf.String())
+	assert.Empty(t, tree.dedup.entries)
+}
+
+func TestStdLogger_DeduplicationShortWindow(t *testing.T) {
+	assert.Equal(t, time.Millisecond, dedupTick(time.Nanosecond))
+	assert.Equal(t, time.Minute, dedupTick(4*time.Minute))
+
+	logger, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithDeduplication(time.Nanosecond)
+	})
+	logger.Info("msg")
+	time.Sleep(2 * time.Millisecond) // lets the ticker fire
+	assert.NoError(t, logger.(*StdLogger).Close())
+	assert.Equal(t, "info root msg\n", buf.String())
+}
+
+// sortedLines sorts the lines of the given text.
+func sortedLines(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
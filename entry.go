@@ -0,0 +1,155 @@
+package mlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry contains a single log message and all associated data.
+type Entry struct {
+	// Time at which the message was logged
+	Time time.Time
+	// Level of the message
+	Level Level
+	// Name of the logger
+	Name string
+	// Message is the formatted log message, without fields
+	Message string
+	// Fields contains all structured data attached to the message.
+	// Does not contain the logger name or the error.
+	Fields Fields
+	// Caller is the call site of the log statement, or nil if unknown
+	Caller *runtime.Frame
+	// StackTrace of the log statement or the error's origin, or nil if not captured
+	StackTrace []runtime.Frame
+	// Error attached to the message, or nil
+	Error error
+	// Context passed to context-aware log methods, or nil
+	Context context.Context
+
+	// errorInMessage is true if the error was passed as log argument and is therefore part of the message.
+	errorInMessage bool
+	// output the entry is written to, or nil if unknown. Used for detecting terminals.
+	output io.Writer
+}
+
+// errorKey is the field key of errors attached via Logger.WithError. Equals logrus.ErrorKey.
+const errorKey = "error"
+
+// argErrorKey is the key of the logrus entry field containing the first error argument of a log method.
+// It is not exposed as a user-defined field.
+const argErrorKey = "mlog.error"
+
+// ErrorType returns the type of the attached error, like "*fs.PathError".
+// Returns an empty string if there is no error.
+func (e *Entry) ErrorType() string {
+	if e.Error == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T", e.Error)
+}
+
+// ErrorChain returns the attached error, followed by all errors it wraps.
+// See errors.Unwrap.
+func (e *Entry) ErrorChain() []error {
+	var chain []error
+	for err := e.Error; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err)
+	}
+	return chain
+}
+
+// firstError returns the first argument that is an error, or nil.
+func firstError(args []interface{}) error {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// The EntryFormatter converts a log entry into a byte representation suitable for output.
+type EntryFormatter func(entry *Entry) ([]byte, error)
+
+// EntryHook is called for outgoing log entries.
+// Note: hooks are not called asynchronously and should therefore be non-blocking.
+// Use LoggerBuilder.WithAsyncHook for blocking hooks.
+type EntryHook func(entry *Entry) error
+
+// EntryFormatter converts the formatter into an EntryFormatter.
+// Fields and errors are appended to the message. The call site and stack traces are not available to the formatter.
+func (f Formatter) EntryFormatter() EntryFormatter {
+	return func(entry *Entry) ([]byte, error) {
+		return f(entry.Time, entry.Level, entry.Name, entry.messageWithFields())
+	}
+}
+
+// EntryHook converts the hook into an EntryHook.
+// Fields and errors are appended to the message.
+func (h Hook) EntryHook() EntryHook {
+	return func(entry *Entry) error {
+		return h(entry.Time, entry.Level, entry.Name, entry.messageWithFields())
+	}
+}
+
+// messageWithFields returns the message with all fields and the error appended.
+func (e *Entry) messageWithFields() string {
+	fields := e.Fields
+	if e.Error != nil && !e.errorInMessage {
+		fields = mergeFields(fields, Fields{logrus.ErrorKey: e.Error})
+	}
+	if len(fields) == 0 {
+		return e.Message
+	}
+	return e.Message + " " + formatFields(fields)
+}
+
+// newEntry converts a logrus entry.
+func newEntry(entry *logrus.Entry) *Entry {
+	fields := logrusDataToFields(entry.Data)
+
+	err := splitErrorField(fields)
+	errorInMessage := false
+	if argErr, ok := entry.Data[argErrorKey].(error); ok && err == nil {
+		err = argErr
+		errorInMessage = true
+	}
+
+	name, _ := entry.Data[nameKey].(string)
+	caller, ok := entry.Data[callerKey].(*runtime.Frame)
+	if !ok {
+		caller = entry.Caller
+	}
+	stack, _ := entry.Data[stackTraceKey].([]runtime.Frame)
+	return &Entry{
+		Time:       entry.Time,
+		Level:      Level(entry.Level),
+		Name:       name,
+		Message:    entry.Message,
+		Fields:     fields,
+		Caller:     caller,
+		StackTrace: stack,
+		Error:      err,
+		Context:    entry.Context,
+
+		errorInMessage: errorInMessage,
+	}
+}
+
+// splitErrorField removes the error attached via Logger.WithError from the fields and returns it.
+// Returns nil if the field is missing or does not contain an error.
+func splitErrorField(fields Fields) error {
+	err, ok := fields[errorKey].(error)
+	if !ok {
+		return nil
+	}
+	delete(fields, errorKey)
+	return err
+}
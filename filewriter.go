@@ -0,0 +1,345 @@
+package mlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationInterval defines when log files are rotated based on time.
+type RotationInterval int
+
+const (
+	// RotateNever disables time-based rotation.
+	RotateNever RotationInterval = iota
+	// RotateHourly rotates log files at the start of every hour.
+	RotateHourly
+	// RotateDaily rotates log files at midnight.
+	RotateDaily
+)
+
+// truncate returns the start of the rotation period containing t.
+func (r RotationInterval) truncate(t time.Time) time.Time {
+	switch r {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// RotatingFileOptions configures a RotatingFileWriter.
+type RotatingFileOptions struct {
+	// MaxSize is the maximum size of the log file in bytes before it is rotated.
+	// Disables size-based rotation if zero.
+	MaxSize int64
+	// Rotation enables time-based rotation.
+	Rotation RotationInterval
+	// MaxBackups is the maximum number of rotated files to keep. Keeps all files if zero.
+	MaxBackups int
+	// MaxAge is the maximum age of rotated files before they are deleted. Keeps all files if zero.
+	MaxAge time.Duration
+	// Compress compresses rotated files using gzip.
+	Compress bool
+	// FileMode is used when creating new log files. Defaults to 0644.
+	FileMode os.FileMode
+}
+
+// backupTimeFormat is used for naming rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+const compressSuffix = ".gz"
+
+// RotatingFileWriter writes into a log file and rotates it based on size and time.
+// Rotated files are renamed to contain the rotation timestamp, e.g. "app-2021-05-15T12-30-00.000.log".
+// The log file is opened on the first write. Missing directories are created.
+type RotatingFileWriter struct {
+	filename string
+	opts     RotatingFileOptions
+	now      func() time.Time
+
+	m      sync.Mutex
+	file   *os.File
+	size   int64
+	period time.Time // start of the rotation period of the current file
+
+	millWg sync.WaitGroup
+	millM  sync.Mutex // serializes compression and cleanup
+}
+
+// NewRotatingFileWriter returns a new writer for the given log file.
+func NewRotatingFileWriter(filename string, opts RotatingFileOptions) *RotatingFileWriter {
+	if opts.FileMode == 0 {
+		opts.FileMode = 0644
+	}
+	return &RotatingFileWriter{
+		filename: filename,
+		opts:     opts,
+		now:      time.Now,
+	}
+}
+
+// Write writes into the log file, rotating it beforehand if necessary.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	now := w.now()
+	needsRotation := w.opts.Rotation != RotateNever && w.opts.Rotation.truncate(now).After(w.period)
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize {
+		needsRotation = true
+	}
+	if needsRotation {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current log file, renames it and opens a new one.
+func (w *RotatingFileWriter) Rotate() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.rotate()
+}
+
+// Reopen closes and reopens the log file without renaming it.
+// Intended for external log rotation, e.g. after receiving SIGHUP from logrotate.
+func (w *RotatingFileWriter) Reopen() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if err := w.close(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// Close closes the log file and waits for pending compressions.
+func (w *RotatingFileWriter) Close() error {
+	w.m.Lock()
+	err := w.close()
+	w.m.Unlock()
+
+	w.millWg.Wait()
+	return err
+}
+
+// open opens or creates the log file.
+// The caller must hold the lock.
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.opts.FileMode)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	w.file = file
+	w.size = stat.Size()
+	w.period = w.opts.Rotation.truncate(w.now())
+	if w.size > 0 {
+		w.period = w.opts.Rotation.truncate(stat.ModTime())
+	}
+	return nil
+}
+
+// close closes the log file.
+// The caller must hold the lock.
+func (w *RotatingFileWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate renames the current log file and opens a new one.
+// The caller must hold the lock.
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	now := w.now()
+	backup := w.backupName(now)
+	if err := os.Rename(w.filename, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.millWg.Add(1)
+	go func() {
+		defer w.millWg.Done()
+		w.mill(backup, now)
+	}()
+	return nil
+}
+
+// backupName returns an unused filename for a log file rotated at the given time.
+func (w *RotatingFileWriter) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		if !fileExists(name) && !fileExists(name+compressSuffix) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (w *RotatingFileWriter) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(w.filename)
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return dir, prefix, ext
+}
+
+// mill compresses the given backup and removes outdated backups.
+func (w *RotatingFileWriter) mill(backup string, now time.Time) {
+	w.millM.Lock()
+	defer w.millM.Unlock()
+
+	if w.opts.Compress {
+		if err := compressFile(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to compress log file: %v\n", err)
+		}
+	}
+	if err := w.removeOutdatedBackups(now); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove old log files: %v\n", err)
+	}
+}
+
+type backupFile struct {
+	path      string
+	timestamp time.Time
+}
+
+// backups returns all rotated log files, newest first.
+func (w *RotatingFileWriter) backups() ([]backupFile, error) {
+	dir, prefix, ext := w.nameParts()
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		timestamp, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{
+			path:      filepath.Join(dir, f.Name()),
+			timestamp: timestamp,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+	return backups, nil
+}
+
+func (w *RotatingFileWriter) removeOutdatedBackups(now time.Time) error {
+	if w.opts.MaxBackups == 0 && w.opts.MaxAge == 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-w.opts.MaxAge)
+	for i, b := range backups {
+		tooMany := w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups
+		tooOld := w.opts.MaxAge > 0 && b.timestamp.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile gzips the given file and removes the original.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	stat, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, stat.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
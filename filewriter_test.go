@@ -0,0 +1,142 @@
+package mlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFileWriter(t *testing.T, opts RotatingFileOptions) (*RotatingFileWriter, string, *time.Time) {
+	dir := t.TempDir()
+	w := NewRotatingFileWriter(filepath.Join(dir, "logs", "app.log"), opts)
+	now := time.Date(2021, 5, 15, 12, 30, 0, 0, time.Local)
+	w.now = func() time.Time {
+		return now
+	}
+	return w, filepath.Join(dir, "logs"), &now
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRotatingFileWriter_MaxSize(t *testing.T) {
+	w, dir, _ := newTestFileWriter(t, RotatingFileOptions{
+		MaxSize:    10,
+		MaxBackups: 2,
+	})
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	files := listFiles(t, dir)
+	assert.Equal(t, []string{
+		"app-2021-05-15T12-30-00.001.log",
+		"app-2021-05-15T12-30-00.002.log",
+		"app.log",
+	}, files)
+	assert.Equal(t, "line 2\n", readFile(t, filepath.Join(dir, files[0])))
+	assert.Equal(t, "line 3\n", readFile(t, filepath.Join(dir, files[1])))
+	assert.Equal(t, "line 4\n", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestRotatingFileWriter_Interval(t *testing.T) {
+	w, dir, now := newTestFileWriter(t, RotatingFileOptions{
+		Rotation: RotateHourly,
+		Compress: true,
+	})
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	*now = now.Add(time.Hour)
+	_, err = w.Write([]byte("third\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"app-2021-05-15T13-30-00.000.log.gz", "app.log"}, listFiles(t, dir))
+	assert.Equal(t, "third\n", readFile(t, filepath.Join(dir, "app.log")))
+
+	f, err := os.Open(filepath.Join(dir, "app-2021-05-15T13-30-00.000.log.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+}
+
+func TestRotatingFileWriter_MaxAge(t *testing.T) {
+	w, dir, now := newTestFileWriter(t, RotatingFileOptions{
+		MaxAge: time.Hour,
+	})
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Rotate())
+	*now = now.Add(2 * time.Hour)
+	require.NoError(t, w.Rotate())
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"app-2021-05-15T14-30-00.000.log", "app.log"}, listFiles(t, dir))
+}
+
+func TestRotatingFileWriter_Reopen(t *testing.T) {
+	w, dir, _ := newTestFileWriter(t, RotatingFileOptions{})
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, os.Rename(filepath.Join(dir, "app.log"), filepath.Join(dir, "app.log.1")))
+	require.NoError(t, w.Reopen())
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "first\n", readFile(t, filepath.Join(dir, "app.log.1")))
+	assert.Equal(t, "second\n", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestStdLogger_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	logger := NewLoggerBuilder("root").
+		WithFileOutput(filename, RotatingFileOptions{}).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(msg + "\n"), nil
+		}).
+		Create().(*StdLogger)
+
+	logger.Info("first")
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, logger.Reopen())
+	logger.New("sub").Info("second")
+	require.NoError(t, logger.Close())
+
+	assert.Equal(t, "first\n", readFile(t, filename+".1"))
+	assert.Equal(t, "second\n", readFile(t, filename))
+}
@@ -2,31 +2,270 @@ package mlog
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// ConsoleFormatter formats log messages for human-readable, colored console output.
+// Use NewConsoleFormatter to print the call site and stack traces.
 func ConsoleFormatter(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
-	ts := timestamp.Format("02.01.2006 15:04:05")
-	lvl := strings.ToUpper(level.String())[:4]
+	return defaultConsoleFormatter(&Entry{
+		Time:    timestamp,
+		Level:   level,
+		Name:    name,
+		Message: msg,
+	})
+}
+
+var defaultConsoleFormatter = NewConsoleFormatter(ConsoleOptions{
+	Color: ColorAlways,
+})
+
+// NameTruncation defines how logger names are shortened if they exceed the name width.
+type NameTruncation int
 
-	ts = colorCode(darkGray, ts)
-	lvl = colorCode(levelColor(level), lvl)
-	sym := colorCode(levelColor(level), "▶")
+const (
+	// TruncateLeft removes characters at the beginning of the name.
+	TruncateLeft NameTruncation = iota
+	// TruncateRight removes characters at the end of the name.
+	TruncateRight
+	// AbbreviateComponents shortens dotted name components to their first character, starting with the first one.
+	// The last component is never abbreviated. Falls back to TruncateLeft if the name is still too long.
+	AbbreviateComponents
+)
+
+// LevelLabel defines how log levels are printed.
+type LevelLabel int
+
+const (
+	// LevelLabelShort prints the first four characters of the level in upper case, e.g. "INFO" or "ERRO".
+	LevelLabelShort LevelLabel = iota
+	// LevelLabelFull prints the full level in upper case, padded to a common width.
+	LevelLabelFull
+	// LevelLabelChar prints the first character of the level in upper case.
+	LevelLabelChar
+)
 
-	name = trimPadLeft(name, 40)
-	line := ts + " " + lvl + " " + name + " " + sym + " " + msg + "\n"
-	return []byte(line), nil
+// ColorMode defines if ANSI color codes are emitted.
+type ColorMode int
+
+const (
+	// ColorAuto enables colors if the output is a terminal and the NO_COLOR environment variable is not set.
+	ColorAuto ColorMode = iota
+	// ColorAlways enables colors.
+	ColorAlways
+	// ColorNever disables colors.
+	ColorNever
+)
+
+// ConsoleOptions configures a console formatter.
+// Zero values are replaced by sensible defaults.
+type ConsoleOptions struct {
+	// TimeLayout is the layout of the timestamp. Defaults to "02.01.2006 15:04:05".
+	TimeLayout string
+	// TimeZone converts timestamps into the given location. Timestamps are not converted if nil.
+	TimeZone *time.Location
+	// Milliseconds appends milliseconds to the timestamp.
+	Milliseconds bool
+
+	// NameWidth is the width of the logger name column. Defaults to 40 if 0.
+	// Names are neither padded nor truncated if negative.
+	NameWidth int
+	// NameTruncation defines how names exceeding the NameWidth are shortened.
+	NameTruncation NameTruncation
+
+	// LevelLabel defines how the log level is printed.
+	LevelLabel LevelLabel
+	// Symbol separates the message from the header. Defaults to "▶".
+	Symbol string
+	// HideSymbol disables the symbol.
+	HideSymbol bool
+
+	// Color defines if ANSI colors are used.
+	Color ColorMode
+	// ColorOutput is checked for being a terminal if Color is ColorAuto.
+	// Defaults to the output the entry is written to, or os.Stderr if the output is unknown.
+	ColorOutput io.Writer
 }
 
+func (o *ConsoleOptions) setDefaults() {
+	if o.TimeLayout == "" {
+		o.TimeLayout = "02.01.2006 15:04:05"
+	}
+	if o.NameWidth == 0 {
+		o.NameWidth = 40
+	}
+	if o.Symbol == "" {
+		o.Symbol = "▶"
+	}
+}
+
+// NewConsoleFormatter returns a formatter for human-readable console output.
+// The call site is printed after the logger name if it is known. See LoggerBuilder.WithCaller.
+// Stack traces are printed on indented lines below the message. See LoggerBuilder.WithStackTrace.
+func NewConsoleFormatter(opts ConsoleOptions) EntryFormatter {
+	opts.setDefaults()
+
+	timeLayout := opts.TimeLayout
+	if opts.Milliseconds {
+		timeLayout += ".000"
+	}
+	colors := newColorDetector(opts.Color, opts.ColorOutput)
+
+	return func(entry *Entry) ([]byte, error) {
+		colorize := func(color, str string) string {
+			return str
+		}
+		if colors.enabled(entry.output) {
+			colorize = colorCode
+		}
+
+		timestamp := entry.Time
+		if opts.TimeZone != nil {
+			timestamp = timestamp.In(opts.TimeZone)
+		}
+
+		ts := colorize(darkGray, timestamp.Format(timeLayout))
+		lvl := colorize(levelColor(entry.Level), levelLabel(entry.Level, opts.LevelLabel))
+		name := formatName(entry.Name, opts.NameWidth, opts.NameTruncation)
+
+		line := ts + " " + lvl + " " + name + " "
+		if entry.Caller != nil {
+			line += colorize(darkGray, shortCaller(entry.Caller)) + " "
+		}
+		if !opts.HideSymbol {
+			line += colorize(levelColor(entry.Level), opts.Symbol) + " "
+		}
+		line += entry.messageWithFields() + "\n"
+		if entry.StackTrace != nil {
+			line += colorize(darkGray, formatStackTrace(entry.StackTrace, "\t")) + "\n"
+		}
+		return []byte(line), nil
+	}
+}
+
+// colorDetector decides if colors are used for a given output.
+// The result is cached per output file.
+type colorDetector struct {
+	mode      ColorMode
+	fixed     *bool // result for a fixed ColorOutput
+	terminals sync.Map
+}
+
+func newColorDetector(mode ColorMode, out io.Writer) *colorDetector {
+	d := &colorDetector{mode: mode}
+	if out != nil {
+		colors := useColors(mode, out)
+		d.fixed = &colors
+	}
+	return d
+}
+
+// enabled returns true if colors should be used for the given output.
+func (d *colorDetector) enabled(out io.Writer) bool {
+	if d.fixed != nil {
+		return *d.fixed
+	}
+	if d.mode != ColorAuto {
+		return useColors(d.mode, out)
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	if colors, ok := d.terminals.Load(file); ok {
+		return colors.(bool)
+	}
+	colors := useColors(d.mode, file)
+	d.terminals.Store(file, colors)
+	return colors
+}
+
+func useColors(mode ColorMode, out io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(out)
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+func levelLabel(level Level, label LevelLabel) string {
+	str := strings.ToUpper(level.String())
+	switch label {
+	case LevelLabelFull:
+		return fmt.Sprintf("%-5s", str)
+	case LevelLabelChar:
+		return str[:1]
+	default:
+		return fmt.Sprintf("%-4s", str)[:4]
+	}
+}
+
+func formatName(name string, width int, truncation NameTruncation) string {
+	if width < 0 {
+		return name
+	}
+	switch truncation {
+	case TruncateRight:
+		return trimPadRight(name, width)
+	case AbbreviateComponents:
+		return trimPadLeft(abbreviateName(name, width), width)
+	default:
+		return trimPadLeft(name, width)
+	}
+}
+
+// trimPadLeft pads the message with spaces on the left side, or removes leading characters if it is too long.
 func trimPadLeft(msg string, length int) string {
 	msg = fmt.Sprintf("%"+strconv.Itoa(length)+"s", msg)
 	return msg[len(msg)-length:]
 }
 
+// trimPadRight pads the message with spaces on the left side, or removes trailing characters if it is too long.
+func trimPadRight(msg string, length int) string {
+	msg = fmt.Sprintf("%"+strconv.Itoa(length)+"s", msg)
+	return msg[:length]
+}
+
+// abbreviateName shortens name components to their first character until the name fits into the given length.
+func abbreviateName(name string, length int) string {
+	components := strings.Split(name, nameSeparator)
+	for i := 0; i < len(components)-1 && len(name) > length; i++ {
+		if len(components[i]) > 0 {
+			components[i] = components[i][:1]
+		}
+		name = strings.Join(components, nameSeparator)
+	}
+	return name
+}
+
 const (
 	red      = "31"
+	magenta  = "35"
 	yellow   = "33"
 	cyan     = "36"
 	gray     = "37"
@@ -43,9 +282,16 @@ func levelColor(level Level) string {
 		return yellow
 	case ErrorLevel:
 		return red
-	default: // unknown
-		return red
+	case FatalLevel, PanicLevel:
+		return magenta
+	}
+	if def, ok := level.definition(); ok {
+		if def.Color != "" {
+			return def.Color
+		}
+		return levelColor(level.closestBuiltin())
 	}
+	return red // unknown
 }
 
 func colorCode(color, str string) string {
@@ -0,0 +1,96 @@
+package mlog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleFormatter(t *testing.T) {
+	ts := time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC)
+	data, err := ConsoleFormatter(ts, WarnLevel, "db.pool", "msg")
+	assert.NoError(t, err)
+	assert.Equal(t, "\x1b[90m15.05.2021 12:30:00\x1b[0m \x1b[33mWARN\x1b[0m                                  db.pool \x1b[33m▶\x1b[0m msg\n", string(data))
+}
+
+func TestNewConsoleFormatter(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Date(2021, 5, 15, 12, 30, 0, 123e6, time.UTC),
+		Level:   ErrorLevel,
+		Name:    "database.connection.pool",
+		Message: "msg",
+		Fields:  Fields{"a": 1},
+	}
+
+	formatter := NewConsoleFormatter(ConsoleOptions{
+		TimeLayout:     "15:04:05",
+		TimeZone:       time.FixedZone("UTC+2", 2*60*60),
+		Milliseconds:   true,
+		NameWidth:      10,
+		NameTruncation: AbbreviateComponents,
+		LevelLabel:     LevelLabelFull,
+		Symbol:         "|",
+		Color:          ColorNever,
+	})
+	data, err := formatter(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "14:30:00.123 ERROR   d.c.pool | msg a=1\n", string(data))
+
+	formatter = NewConsoleFormatter(ConsoleOptions{
+		NameWidth:  -1,
+		LevelLabel: LevelLabelChar,
+		HideSymbol: true,
+		Color:      ColorAuto,
+	})
+	data, err = formatter(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "15.05.2021 12:30:00 E database.connection.pool msg a=1\n", string(data))
+}
+
+func Test_formatName(t *testing.T) {
+	assert.Equal(t, "   a.b", formatName("a.b", 6, TruncateLeft))
+	assert.Equal(t, "b.c.de", formatName("aaa.b.c.de", 6, TruncateLeft))
+	assert.Equal(t, "aaa.b.", formatName("aaa.b.c.de", 6, TruncateRight))
+	assert.Equal(t, "a.b.c.de", formatName("aaa.b.c.de", 8, AbbreviateComponents))
+	assert.Equal(t, "  aaa.bbb", formatName("aaa.bbb", 9, AbbreviateComponents))
+	assert.Equal(t, "ccccc", formatName("a.ccccc", 5, AbbreviateComponents))
+	assert.Equal(t, "abc", formatName("abc", -1, TruncateLeft))
+}
+
+func Test_useColors(t *testing.T) {
+	assert.True(t, useColors(ColorAlways, nil))
+	assert.False(t, useColors(ColorNever, nil))
+	assert.False(t, useColors(ColorAuto, nil))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, useColors(ColorAuto, os.Stdout))
+}
+
+func Test_colorDetector(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	auto := newColorDetector(ColorAuto, nil)
+	assert.False(t, auto.enabled(file))
+	assert.False(t, auto.enabled(&bytes.Buffer{}))
+	assert.True(t, newColorDetector(ColorAlways, nil).enabled(file))
+	assert.False(t, newColorDetector(ColorNever, os.Stdout).enabled(nil))
+
+	buf := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithEntryFormatter(NewConsoleFormatter(ConsoleOptions{})).
+		Create()
+	logger.Info("msg")
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func Test_levelColor(t *testing.T) {
+	assert.Equal(t, red, levelColor(ErrorLevel))
+	assert.Equal(t, magenta, levelColor(FatalLevel))
+	assert.Equal(t, magenta, levelColor(PanicLevel))
+}
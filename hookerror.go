@@ -0,0 +1,139 @@
+package mlog
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HookError describes a failed hook invocation.
+type HookError struct {
+	// Hook is the index of the failed hook, in registration order.
+	Hook int
+	// Entry that was passed to the hook
+	Entry *Entry
+	// Err returned by the hook
+	Err error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("hook %d: %v", e.Hook, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// HookErrorHandler is called if a hook returns an error.
+// Without a handler, errors are printed to stderr by logrus.
+type HookErrorHandler func(err *HookError)
+
+// PrintHookErrors returns a handler that prints hook errors to the given writer.
+func PrintHookErrors(w io.Writer) HookErrorHandler {
+	return func(err *HookError) {
+		fmt.Fprintf(w, "Failed to fire hook: %v\n", err)
+	}
+}
+
+// SuppressHookErrors returns a handler that ignores hook errors.
+// Failures are still counted in the hook statistics.
+func SuppressHookErrors() HookErrorHandler {
+	return func(*HookError) {}
+}
+
+// hookErrorKey marks entries logged by LogHookErrors.
+const hookErrorKey = "hook_error"
+
+// LogHookErrors returns a handler that logs hook errors into the given fallback logger.
+// Errors that occur while logging hook errors are ignored to prevent endless recursion.
+func LogHookErrors(fallback Logger) HookErrorHandler {
+	return func(err *HookError) {
+		if _, ok := err.Entry.Fields[hookErrorKey]; ok {
+			return
+		}
+		fallback.With(hookErrorKey, err.Hook).Errorf("Failed to fire hook: %v", err.Err)
+	}
+}
+
+// HookStats contains invocation statistics of a single hook.
+type HookStats struct {
+	// Hook is the index of the hook, in registration order.
+	Hook int
+	// Calls is the number of invocations.
+	Calls uint64
+	// Failures is the number of invocations that returned an error.
+	Failures uint64
+	// LastError is the most recent error, or nil.
+	LastError error
+	// LastFailure is the time of the most recent error.
+	LastFailure time.Time
+	// Dropped is the number of entries discarded by asynchronous hooks. See LoggerBuilder.WithAsyncHook.
+	Dropped uint64
+}
+
+// hookState tracks the invocations of a single hook.
+type hookState struct {
+	tree     *loggerTree
+	index    int
+	calls    uint64 // accessed atomically
+	failures uint64 // accessed atomically
+
+	m           sync.Mutex
+	lastErr     error
+	lastFailure time.Time
+	dropped     func() uint64 // nil if the hook is synchronous
+}
+
+// wrap returns a hook that tracks invocations of the given hook and passes errors to the error handler.
+// Returns the hook's error if no handler is configured.
+func (s *hookState) wrap(hook EntryHook) EntryHook {
+	return func(entry *Entry) error {
+		atomic.AddUint64(&s.calls, 1)
+		err := hook(entry)
+		if err == nil {
+			return nil
+		}
+
+		atomic.AddUint64(&s.failures, 1)
+		s.m.Lock()
+		s.lastErr = err
+		s.lastFailure = time.Now()
+		s.m.Unlock()
+
+		handler := s.tree.hookErrorHandler
+		if handler == nil {
+			return err
+		}
+		handler(&HookError{
+			Hook:  s.index,
+			Entry: entry,
+			Err:   err,
+		})
+		return nil
+	}
+}
+
+// setAsync reports the entries dropped by the given asynchronous hook.
+func (s *hookState) setAsync(hook *AsyncHook) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.dropped = hook.Dropped
+}
+
+func (s *hookState) stats() HookStats {
+	s.m.Lock()
+	defer s.m.Unlock()
+	stats := HookStats{
+		Hook:        s.index,
+		Calls:       atomic.LoadUint64(&s.calls),
+		Failures:    atomic.LoadUint64(&s.failures),
+		LastError:   s.lastErr,
+		LastFailure: s.lastFailure,
+	}
+	if s.dropped != nil {
+		stats.Dropped = s.dropped()
+	}
+	return stats
+}
@@ -0,0 +1,79 @@
+package mlog
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerBuilder_WithHookErrorHandler(t *testing.T) {
+	hookErr := errors.New("hook failure")
+
+	var handled []*HookError
+	calls := 0
+	logger := NewLoggerBuilder("root").
+		WithOutput(ioutil.Discard).
+		WithHookErrorHandler(func(err *HookError) {
+			handled = append(handled, err)
+		}).
+		WithEntryHook(AllLevels, func(entry *Entry) error {
+			return hookErr
+		}).
+		WithEntryHook(AllLevels, func(entry *Entry) error {
+			calls++
+			return nil
+		}).
+		Create().(*StdLogger)
+
+	logger.Info("a")
+	logger.Warn("b")
+
+	assert.Equal(t, 2, calls, "subsequent hooks must still be called")
+	assert.Len(t, handled, 2)
+	assert.Equal(t, 0, handled[0].Hook)
+	assert.Equal(t, "a", handled[0].Entry.Message)
+	assert.True(t, errors.Is(handled[1], hookErr))
+
+	stats := logger.HookStats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, uint64(2), stats[0].Calls)
+	assert.Equal(t, uint64(2), stats[0].Failures)
+	assert.Equal(t, hookErr, stats[0].LastError)
+	assert.False(t, stats[0].LastFailure.IsZero())
+	assert.Equal(t, 1, stats[1].Hook)
+	assert.Equal(t, uint64(2), stats[1].Calls)
+	assert.Zero(t, stats[1].Failures)
+}
+
+func TestLoggerBuilder_WithHookErrorHandler_Async(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(ioutil.Discard).
+		WithHookErrorHandler(PrintHookErrors(buf)).
+		WithAsyncHook(AllLevels, func(entry *Entry) error {
+			return errors.New("async failure")
+		}, AsyncHookOptions{}).
+		Create().(*StdLogger)
+
+	logger.Info("a")
+	assert.NoError(t, logger.Close())
+
+	assert.Equal(t, "Failed to fire hook: hook 0: async failure\n", buf.String())
+	assert.Equal(t, uint64(1), logger.HookStats()[0].Failures)
+}
+
+func TestLogHookErrors(t *testing.T) {
+	fallback := NewMemLogger()
+	handler := LogHookErrors(fallback)
+
+	handler(&HookError{Hook: 3, Entry: &Entry{}, Err: errors.New("failure")})
+	fallback.AssertAllMessages(t, ErrorLevel, "Failed to fire hook: failure")
+	assert.Equal(t, []Fields{{hookErrorKey: 3}}, fallback.Fields(ErrorLevel))
+
+	// errors of entries logged by the handler are ignored
+	handler(&HookError{Hook: 3, Entry: &Entry{Fields: Fields{hookErrorKey: 3}}, Err: errors.New("failure")})
+	assert.Equal(t, 1, fallback.LogCount())
+}
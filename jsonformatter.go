@@ -0,0 +1,184 @@
+package mlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// JSONOptions configures a JSON formatter.
+// Zero values are replaced by sensible defaults.
+type JSONOptions struct {
+	// TimeFormat is the layout of the timestamp. Defaults to time.RFC3339Nano.
+	TimeFormat string
+	// TimeKey is the key of the timestamp. Defaults to "time".
+	TimeKey string
+	// LevelKey is the key of the log level. Defaults to "level".
+	LevelKey string
+	// NameKey is the key of the logger name. Defaults to "name".
+	NameKey string
+	// MessageKey is the key of the log message. Defaults to "msg".
+	MessageKey string
+	// ErrorKey is the key of the attached error. Defaults to "error".
+	ErrorKey string
+	// ErrorTypeKey is the key of the attached error's type. Defaults to "error_type".
+	ErrorTypeKey string
+	// ErrorChainKey is the key of the wrapped errors. Defaults to "error_chain".
+	// Each wrapped error is an object with the keys ErrorKey and ErrorTypeKey.
+	ErrorChainKey string
+	// CallerKey is the key of the caller. Defaults to "caller".
+	CallerKey string
+	// StackTraceKey is the key of the stack trace. Defaults to "stacktrace".
+	StackTraceKey string
+	// FieldsKey nests all fields within an object with the given key.
+	// If empty, fields are flattened into the top-level object.
+	// Flattened fields that clash with other keys are prefixed with "fields.".
+	FieldsKey string
+}
+
+func (o *JSONOptions) setDefaults() {
+	setDefault := func(val *string, def string) {
+		if *val == "" {
+			*val = def
+		}
+	}
+	setDefault(&o.TimeFormat, time.RFC3339Nano)
+	setDefault(&o.TimeKey, "time")
+	setDefault(&o.LevelKey, "level")
+	setDefault(&o.NameKey, "name")
+	setDefault(&o.MessageKey, "msg")
+	setDefault(&o.ErrorKey, "error")
+	setDefault(&o.ErrorTypeKey, "error_type")
+	setDefault(&o.ErrorChainKey, "error_chain")
+	setDefault(&o.CallerKey, "caller")
+	setDefault(&o.StackTraceKey, "stacktrace")
+}
+
+// JSONFormatter formats entries as JSON objects, one per line, using default options.
+// It is an EntryFormatter and is configured via LoggerBuilder.WithEntryFormatter, not WithConsoleFormatter.
+func JSONFormatter(entry *Entry) ([]byte, error) {
+	return defaultJSONFormatter(entry)
+}
+
+var defaultJSONFormatter = NewJSONFormatter(JSONOptions{})
+
+// NewJSONFormatter returns a formatter that outputs entries as JSON objects, one per line.
+// Values that cannot be marshalled are converted to strings.
+func NewJSONFormatter(opts JSONOptions) EntryFormatter {
+	opts.setDefaults()
+
+	return func(entry *Entry) ([]byte, error) {
+		obj := newJSONObject()
+		obj.add(opts.TimeKey, entry.Time.Format(opts.TimeFormat))
+		obj.add(opts.LevelKey, entry.Level.String())
+		obj.add(opts.NameKey, entry.Name)
+		obj.add(opts.MessageKey, entry.Message)
+		if entry.Error != nil {
+			obj.add(opts.ErrorKey, entry.Error.Error())
+			obj.add(opts.ErrorTypeKey, entry.ErrorType())
+			if chain := entry.ErrorChain(); len(chain) > 1 {
+				obj.add(opts.ErrorChainKey, jsonErrorChain(chain[1:], opts))
+			}
+		}
+		if entry.Caller != nil {
+			obj.add(opts.CallerKey, entry.Caller.File+":"+strconv.Itoa(entry.Caller.Line))
+		}
+		if entry.StackTrace != nil {
+			obj.add(opts.StackTraceKey, formatStackTrace(entry.StackTrace, ""))
+		}
+
+		if len(entry.Fields) > 0 {
+			if opts.FieldsKey != "" {
+				fields := newJSONObject()
+				fields.addFields(entry.Fields)
+				obj.add(opts.FieldsKey, json.RawMessage(fields.bytes()))
+			} else {
+				obj.addFields(entry.Fields)
+			}
+		}
+
+		return append(obj.bytes(), '\n'), nil
+	}
+}
+
+// jsonErrorChain returns the errors as JSON array.
+func jsonErrorChain(chain []error, opts JSONOptions) json.RawMessage {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, err := range chain {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		obj := newJSONObject()
+		obj.add(opts.ErrorKey, err.Error())
+		obj.add(opts.ErrorTypeKey, fmt.Sprintf("%T", err))
+		buf.Write(obj.bytes())
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// jsonObject writes a JSON object with a deterministic key order.
+type jsonObject struct {
+	buf  bytes.Buffer
+	keys map[string]struct{}
+}
+
+func newJSONObject() *jsonObject {
+	o := &jsonObject{
+		keys: make(map[string]struct{}),
+	}
+	o.buf.WriteByte('{')
+	return o
+}
+
+// add appends a key/value pair to the object.
+func (o *jsonObject) add(key string, value interface{}) {
+	if len(o.keys) > 0 {
+		o.buf.WriteByte(',')
+	}
+	o.keys[key] = struct{}{}
+
+	k, _ := json.Marshal(key)
+	o.buf.Write(k)
+	o.buf.WriteByte(':')
+	o.buf.Write(marshalJSONValue(value))
+}
+
+// addFields appends all fields, sorted by key.
+// Keys that already exist are prefixed with "fields.".
+func (o *jsonObject) addFields(fields Fields) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := k
+		if _, exists := o.keys[key]; exists {
+			key = "fields." + key
+		}
+		o.add(key, fields[k])
+	}
+}
+
+func (o *jsonObject) bytes() []byte {
+	return append(o.buf.Bytes(), '}')
+}
+
+func marshalJSONValue(value interface{}) []byte {
+	if err, ok := value.(error); ok {
+		if _, isMarshaler := value.(json.Marshaler); !isMarshaler {
+			value = err.Error()
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	return data
+}
@@ -0,0 +1,57 @@
+package mlog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:   InfoLevel,
+		Name:    "db.pool",
+		Message: "connected \"db\"",
+		Fields: Fields{
+			"b":   2,
+			"a":   "x",
+			"msg": "clash",
+			"inf": math.Inf(1),
+			"err": errors.New("failure"),
+		},
+	}
+
+	data, err := JSONFormatter(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"time":"2021-05-15T12:30:00Z","level":"info","name":"db.pool","msg":"connected \"db\"",`+
+		`"a":"x","b":2,"err":"failure","inf":"+Inf","fields.msg":"clash"}`+"\n", string(data))
+}
+
+func TestNewJSONFormatter(t *testing.T) {
+	formatter := NewJSONFormatter(JSONOptions{
+		TimeFormat: "15:04",
+		TimeKey:    "ts",
+		LevelKey:   "lvl",
+		NameKey:    "logger",
+		MessageKey: "message",
+		FieldsKey:  "fields",
+	})
+
+	entry := &Entry{
+		Time:    time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:   WarnLevel,
+		Name:    "db",
+		Message: "msg",
+		Fields:  Fields{"a": 1},
+		Error:   fmt.Errorf("wrapped: %w", errors.New("failure")),
+	}
+
+	data, err := formatter(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ts":"12:30","lvl":"warn","logger":"db","message":"msg","error":"wrapped: failure","error_type":"*fmt.wrapError",`+
+		`"error_chain":[{"error":"failure","error_type":"*errors.errorString"}],"fields":{"a":1}}`+"\n", string(data))
+}
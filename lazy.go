@@ -0,0 +1,93 @@
+package mlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// Lazy is a log argument or field value that is only computed if the message is actually logged.
+// Useful for expensive values of messages with verbose levels:
+//
+//	logger.Debugf("state: %v", mlog.Lazy(func() interface{} { return dumpState() }))
+//
+// StdLogger and MemLogger call the function once per logged message, on the logging goroutine,
+// before the message is passed to formatters and hooks.
+// Elsewhere, the function is called each time the value is formatted.
+type Lazy func() interface{}
+
+// String returns the formatted value.
+func (l Lazy) String() string {
+	return fmt.Sprint(l())
+}
+
+// Format formats the value according to the verb and flags. Implements fmt.Formatter.
+func (l Lazy) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, formatDirective(f, verb), l())
+}
+
+// MarshalJSON marshals the value. Implements json.Marshaler.
+func (l Lazy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l())
+}
+
+// LogValue returns the value for slog handlers. Implements slog.LogValuer.
+func (l Lazy) LogValue() slog.Value {
+	return slog.AnyValue(l())
+}
+
+// resolveLazy returns the arguments with all Lazy values replaced by their results.
+// The slice is only copied if it contains Lazy values.
+func resolveLazy(args []interface{}) []interface{} {
+	resolved := args
+	copied := false
+	for i, arg := range args {
+		lazy, ok := arg.(Lazy)
+		if !ok {
+			continue
+		}
+		if !copied {
+			resolved = append([]interface{}(nil), args...)
+			copied = true
+		}
+		resolved[i] = lazy()
+	}
+	return resolved
+}
+
+// resolveLazyFields returns the fields with all Lazy values replaced by their results.
+// The fields are only copied if they contain Lazy values.
+func resolveLazyFields(fields Fields) Fields {
+	resolved := fields
+	copied := false
+	for k, v := range fields {
+		lazy, ok := v.(Lazy)
+		if !ok {
+			continue
+		}
+		if !copied {
+			resolved = mergeFields(fields, nil)
+			copied = true
+		}
+		resolved[k] = lazy()
+	}
+	return resolved
+}
+
+// formatDirective reconstructs the formatting directive, like "%+8.2f".
+func formatDirective(f fmt.State, verb rune) string {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+	if prec, ok := f.Precision(); ok {
+		directive += "." + strconv.Itoa(prec)
+	}
+	return directive + string(verb)
+}
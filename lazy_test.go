@@ -0,0 +1,142 @@
+package mlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	lazy := Lazy(func() interface{} {
+		calls++
+		return 3.14159
+	})
+
+	assert.Equal(t, "3.14159", lazy.String())
+	assert.Equal(t, "[  3.1]", fmt.Sprintf("[%5.1f]", lazy))
+	assert.Equal(t, "3.14159", fmt.Sprint(lazy))
+	data, err := json.Marshal(map[string]interface{}{"v": lazy})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"v":3.14159}`, string(data))
+	assert.Equal(t, 4, calls)
+}
+
+func TestStdLogger_Lazy(t *testing.T) {
+	logger, buf := newTestLogger("root")
+
+	calls := 0
+	lazy := Lazy(func() interface{} {
+		calls++
+		return "expensive"
+	})
+	fn := func() string {
+		calls++
+		return "expensive"
+	}
+
+	logger.Debugf("value: %v", lazy)
+	logger.With("k", lazy).Debug("msg")
+	logger.DebugFn(fn)
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, buf.String())
+
+	logger.Infof("value: %q", lazy)
+	logger.With("k", lazy).Info("msg")
+	logger.WarnFn(fn)
+	logger.LogFn(ErrorLevel, fn)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, "info root value: \"expensive\"\n"+
+		"info root msg k=expensive\n"+
+		"warn root expensive\n"+
+		"error root expensive\n", buf.String())
+}
+
+func TestStdLogger_LazyAsync(t *testing.T) {
+	var m sync.Mutex
+	var asyncEntries []*Entry
+	sink := &bytes.Buffer{}
+	logger, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithAsyncHook(AllLevels, func(entry *Entry) error {
+			m.Lock()
+			defer m.Unlock()
+			asyncEntries = append(asyncEntries, entry)
+			return nil
+		}, AsyncHookOptions{})
+		b.AddSink(sink, InfoLevel, func(entry *Entry) ([]byte, error) {
+			return []byte(entry.messageWithFields() + "\n"), nil
+		})
+	})
+
+	calls := 0 // not synchronized; the race detector reports calls from other goroutines
+	arg := Lazy(func() interface{} {
+		calls++
+		return "arg"
+	})
+	field := Lazy(func() interface{} {
+		calls++
+		return "field"
+	})
+
+	logger.With("k", field).Infof("v=%v", arg)
+	assert.Equal(t, 2, calls)
+
+	assert.NoError(t, logger.(*StdLogger).Flush(context.Background()))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "info root v=arg k=field\n", buf.String())
+	assert.Equal(t, "v=arg k=field\n", sink.String())
+	m.Lock()
+	defer m.Unlock()
+	if assert.Len(t, asyncEntries, 1) {
+		assert.Equal(t, "v=arg", asyncEntries[0].Message)
+		assert.Equal(t, "field", asyncEntries[0].Fields["k"])
+	}
+}
+
+func TestMemLogger_Lazy(t *testing.T) {
+	logger := NewMemLogger()
+
+	calls := 0
+	lazy := Lazy(func() interface{} {
+		calls++
+		return "expensive"
+	})
+
+	logger.With("k", lazy).Infof("value: %v", lazy)
+	logger.Warn("value: ", lazy)
+	assert.Equal(t, 3, calls)
+
+	logger.AssertAllMessages(t, InfoLevel, "value: expensive")
+	logger.AssertAllMessages(t, WarnLevel, "value: expensive")
+	assert.Equal(t, "expensive", logger.Entries(InfoLevel)[0].Fields["k"])
+}
+
+func TestNOPLogger_Lazy(t *testing.T) {
+	lazy := Lazy(func() interface{} {
+		t.Error("unexpected call")
+		return nil
+	})
+	NOPLogger.With("k", lazy).Infof("value: %v", lazy)
+	NOPLogger.Error(lazy)
+}
+
+func TestMemLogger_Fn(t *testing.T) {
+	logger := NewMemLogger()
+	logger.TraceFn(func() string { return "trace" })
+	logger.ErrorFn(func() string { return "error" })
+
+	logger.AssertAllMessages(t, TraceLevel, "trace")
+	logger.AssertAllMessages(t, ErrorLevel, "error")
+}
+
+func TestNOPLogger_Fn(t *testing.T) {
+	NOPLogger.InfoFn(func() string {
+		t.Error("unexpected call")
+		return ""
+	})
+}
@@ -7,10 +7,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// Level represents the severity of a log message
+// Level represents the severity of a log message.
+// Additional levels can be added via RegisterLevel.
 type Level logrus.Level
 
 const (
+	// PanicLevel level. Highest level of severity. Logs and then panics with the message.
+	PanicLevel = Level(logrus.PanicLevel)
+	// FatalLevel level. Logs and then terminates the program, even if the logging level is set to Panic.
+	FatalLevel = Level(logrus.FatalLevel)
 	// ErrorLevel level. Logs. Used for errors that should definitely be noted.
 	// Commonly used for hooks to send errors to an error tracking service.
 	ErrorLevel = Level(logrus.ErrorLevel)
@@ -24,8 +29,11 @@ const (
 	TraceLevel = Level(logrus.TraceLevel)
 )
 
-// AllLevels exposes all logging levels
+// AllLevels exposes all logging levels, ordered from the most to the least severe one.
+// Includes custom levels.
 var AllLevels = []Level{
+	PanicLevel,
+	FatalLevel,
 	ErrorLevel,
 	WarnLevel,
 	InfoLevel,
@@ -34,6 +42,8 @@ var AllLevels = []Level{
 }
 
 var levelStrings = map[Level]string{
+	PanicLevel: "panic",
+	FatalLevel: "fatal",
 	ErrorLevel: "error",
 	WarnLevel:  "warn",
 	InfoLevel:  "info",
@@ -42,21 +52,30 @@ var levelStrings = map[Level]string{
 }
 
 func (l Level) String() string {
-	str, ok := levelStrings[l]
-	if !ok {
-		return "unknown"
+	if str, ok := levelStrings[l]; ok {
+		return str
 	}
-	return str
+	if def, ok := l.definition(); ok {
+		return def.Name
+	}
+	return "unknown"
 }
 
 // LessSevereThan returns true if the level is less severe than another log level.
 func (l Level) LessSevereThan(other Level) bool {
-	return l > other
+	if l.isBuiltin() && other.isBuiltin() {
+		return l > other
+	}
+	return l.Severity() < other.Severity()
 }
 
 // ParseLevel takes a string and returns the matching log level.
 func ParseLevel(level string) (Level, error) {
 	switch strings.ToLower(level) {
+	case "panic":
+		return PanicLevel, nil
+	case "fatal":
+		return FatalLevel, nil
 	case "error":
 		return ErrorLevel, nil
 	case "warn", "warning":
@@ -68,6 +87,9 @@ func ParseLevel(level string) (Level, error) {
 	case "trace":
 		return TraceLevel, nil
 	}
+	if lvl, ok := loadLevelTable().byName[strings.ToLower(level)]; ok {
+		return lvl, nil
+	}
 
 	var zeroVal Level
 	return zeroVal, fmt.Errorf("invalid log level: %q", level)
@@ -12,6 +12,8 @@ func Test_LessSevereThan(t *testing.T) {
 
 	assert.False(t, WarnLevel.LessSevereThan(WarnLevel))
 	assert.False(t, WarnLevel.LessSevereThan(InfoLevel))
+	assert.True(t, ErrorLevel.LessSevereThan(FatalLevel))
+	assert.True(t, FatalLevel.LessSevereThan(PanicLevel))
 }
 
 func Test_ParseLevel(t *testing.T) {
@@ -21,6 +23,8 @@ func Test_ParseLevel(t *testing.T) {
 	assert.Equal(t, WarnLevel, mustParseLevel(t, "warn"))
 	assert.Equal(t, WarnLevel, mustParseLevel(t, "warning"))
 	assert.Equal(t, ErrorLevel, mustParseLevel(t, "error"))
+	assert.Equal(t, FatalLevel, mustParseLevel(t, "fatal"))
+	assert.Equal(t, PanicLevel, mustParseLevel(t, "panic"))
 
 	assert.Equal(t, ErrorLevel, mustParseLevel(t, "ErRoR"))
 
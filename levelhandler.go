@@ -0,0 +1,155 @@
+package mlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// LevelHandler is a http.Handler for inspecting and changing log levels at runtime.
+// It covers all loggers of a logger tree that were created via NewStdLogger, LoggerBuilder.Create, New or Clone.
+//
+// Loggers are addressed by the "name" query parameter:
+//
+//	GET               lists all loggers and their current levels.
+//	GET    ?name=db   returns the levels of all loggers named "db".
+//	PUT    ?name=db   changes the level of all loggers named "db".
+//	                  Fails with 409 Conflict if the level registry holds a level for "db" or one of its parents,
+//	                  which would override the change.
+//	                  If "recursive" is set, the level is stored in the level registry and
+//	                  also applies to all current and future sub-loggers.
+//	DELETE ?name=db   removes the level of "db" from the level registry.
+//
+// PUT requests expect a JSON body in the form of {"level": "debug", "recursive": true}.
+// Responses are JSON arrays in the form of [{"name": "db", "level": "debug"}].
+// Names are listed once per distinct level, as clones of a logger have their own levels.
+type LevelHandler struct {
+	tree *loggerTree
+}
+
+// NewLevelHandler returns a handler controlling the levels of the given logger's tree.
+func NewLevelHandler(logger *StdLogger) *LevelHandler {
+	return &LevelHandler{
+		tree: logger.tree,
+	}
+}
+
+type loggerLevel struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// maxLevelRequestSize is the maximum size of PUT request bodies.
+const maxLevelRequestSize = 1 << 10
+
+type levelRequest struct {
+	Level     string `json:"level"`
+	Recursive bool   `json:"recursive"`
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	_, hasName := query["name"]
+	name := query.Get("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		if !hasName {
+			h.writeLevels(w, h.tree.loggerNames())
+			return
+		}
+		if len(h.tree.lookupLevelStates(name)) == 0 {
+			http.Error(w, fmt.Sprintf("unknown logger %q", name), http.StatusNotFound)
+			return
+		}
+		h.writeLevels(w, []string{name})
+
+	case http.MethodPut:
+		if !hasName {
+			http.Error(w, "missing logger name", http.StatusBadRequest)
+			return
+		}
+		var req levelRequest
+		body := http.MaxBytesReader(w, r.Body, maxLevelRequestSize)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+			return
+		}
+		level, err := ParseLevel(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.Recursive {
+			h.tree.levels.SetLevel(name, level)
+			h.writeLevels(w, h.subLoggerNames(name))
+			return
+		}
+		states := h.tree.lookupLevelStates(name)
+		if len(states) == 0 {
+			http.Error(w, fmt.Sprintf("unknown logger %q", name), http.StatusNotFound)
+			return
+		}
+		if regName, regLevel, ok := h.tree.levels.entry(name); ok {
+			http.Error(w, fmt.Sprintf("level of logger %q is overridden by level registry entry %q (%s)",
+				name, regName, regLevel), http.StatusConflict)
+			return
+		}
+		for _, state := range states {
+			state.set(level)
+		}
+		h.writeLevels(w, []string{name})
+
+	case http.MethodDelete:
+		if !hasName {
+			http.Error(w, "missing logger name", http.StatusBadRequest)
+			return
+		}
+		h.tree.levels.UnsetLevel(name)
+		h.writeLevels(w, h.subLoggerNames(name))
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// subLoggerNames returns the names of all loggers with the given name or name prefix.
+func (h *LevelHandler) subLoggerNames(name string) []string {
+	var names []string
+	for _, loggerName := range h.tree.loggerNames() {
+		if isSubLoggerName(loggerName, name) {
+			names = append(names, loggerName)
+		}
+	}
+	return names
+}
+
+// writeLevels responds with the given logger names and their distinct levels, sorted by name.
+func (h *LevelHandler) writeLevels(w http.ResponseWriter, names []string) {
+	levels := make([]loggerLevel, 0, len(names))
+	for _, name := range names {
+		seen := make(map[Level]bool)
+		for _, state := range h.tree.lookupLevelStates(name) {
+			level := h.tree.level(name, state)
+			if seen[level] {
+				continue
+			}
+			seen[level] = true
+			levels = append(levels, loggerLevel{
+				Name:  name,
+				Level: level.String(),
+			})
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if levels[i].Name != levels[j].Name {
+			return levels[i].Name < levels[j].Name
+		}
+		return levels[i].Level < levels[j].Level
+	})
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levels)
+}
@@ -0,0 +1,127 @@
+package mlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	root := NewLoggerBuilder("root").Create().(*StdLogger)
+	db := root.New("db")
+	pool := db.New("pool")
+	root.New("http")
+
+	handler := NewLevelHandler(root)
+	request := func(method, query, body string) (int, string) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/levels"+query, strings.NewReader(body))
+		handler.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := request(http.MethodGet, "", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `[{"name":"root","level":"info"},{"name":"root.db","level":"info"},
+		{"name":"root.db.pool","level":"info"},{"name":"root.http","level":"info"}]`, body)
+
+	code, body = request(http.MethodPut, "?name=root.db", `{"level":"debug"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `[{"name":"root.db","level":"debug"}]`, body)
+	assert.Equal(t, DebugLevel, db.Level())
+	assert.Equal(t, InfoLevel, pool.Level())
+
+	code, body = request(http.MethodPut, "?name=root.db", `{"level":"trace","recursive":true}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `[{"name":"root.db","level":"trace"},{"name":"root.db.pool","level":"trace"}]`, body)
+	assert.Equal(t, TraceLevel, db.New("future").Level())
+
+	code, body = request(http.MethodGet, "?name=root.db.pool", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `[{"name":"root.db.pool","level":"trace"}]`, body)
+
+	code, body = request(http.MethodDelete, "?name=root.db", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `[{"name":"root.db","level":"debug"},{"name":"root.db.future","level":"debug"},
+		{"name":"root.db.pool","level":"info"}]`, body)
+
+	code, _ = request(http.MethodGet, "?name=unknown", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = request(http.MethodPut, "?name=root", `{"level":"invalid"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = request(http.MethodPut, "", `{"level":"info"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = request(http.MethodPost, "", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	body = `{"level":"debug","padding":"` + strings.Repeat("x", maxLevelRequestSize) + `"}`
+	code, _ = request(http.MethodPut, "?name=root", body)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, InfoLevel, root.Level())
+}
+
+func TestLevelHandler_BoundedLoggers(t *testing.T) {
+	root := NewLoggerBuilder("root").Create().(*StdLogger)
+	for i := 0; i < 1000; i++ {
+		sub := root.New("sub").With("request", i)
+		sub.New("child").(*StdLogger).Clone()
+		root.Clone()
+	}
+	assert.Eventually(t, func() bool {
+		runtime.GC() // untracks the clones
+		return trackedLoggerNames(root.tree) == 3
+	}, time.Second, time.Millisecond)
+
+	sub := root.New("sub")
+	root.New("sub").SetLevel(DebugLevel)
+	assert.Equal(t, DebugLevel, sub.Level())
+}
+
+// trackedLoggerNames returns the number of tracked logger names of all level scopes.
+func trackedLoggerNames(tree *loggerTree) int {
+	tree.m.Lock()
+	defer tree.m.Unlock()
+	count := 0
+	for _, states := range tree.levelScopes {
+		count += len(states)
+	}
+	return count
+}
+
+func TestLevelHandler_Clones(t *testing.T) {
+	builder := NewLoggerBuilder("root")
+	root := builder.Create().(*StdLogger)
+	clone := root.Clone()
+	clone.SetLevel(WarnLevel)
+
+	rec := httptest.NewRecorder()
+	NewLevelHandler(root).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/levels", nil))
+	assert.JSONEq(t, `[{"name":"root","level":"info"},{"name":"root","level":"warn"}]`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/levels?name=root", strings.NewReader(`{"level":"debug"}`))
+	NewLevelHandler(root).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"name":"root","level":"debug"}]`, rec.Body.String())
+	assert.Equal(t, DebugLevel, root.Level())
+	assert.Equal(t, DebugLevel, clone.Level())
+}
+
+func TestLevelHandler_RegistryConflict(t *testing.T) {
+	root := NewLoggerBuilder("root").
+		WithNameLevel("root", WarnLevel).
+		Create().(*StdLogger)
+	db := root.New("db")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/levels?name=root.db", strings.NewReader(`{"level":"debug"}`))
+	NewLevelHandler(root).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `level registry entry "root" (warn)`)
+	assert.Equal(t, WarnLevel, db.Level())
+}
@@ -0,0 +1,108 @@
+package mlog
+
+import (
+	"strings"
+	"sync"
+)
+
+// LevelRegistry configures log levels based on logger names.
+// A level registered for a name applies to the logger with that name and all of its sub-loggers,
+// with the most specific name taking precedence.
+// The empty name matches all loggers.
+//
+// Levels within the registry take precedence over levels set via Logger.SetLevel.
+// Changes apply immediately to all existing and future loggers using the registry.
+type LevelRegistry struct {
+	m      sync.RWMutex
+	levels map[string]Level
+}
+
+// NewLevelRegistry returns a new, empty level registry.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{
+		levels: make(map[string]Level),
+	}
+}
+
+// SetLevel sets the log level of the logger with the given name and all its sub-loggers.
+func (r *LevelRegistry) SetLevel(name string, level Level) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.levels[name] = level
+}
+
+// UnsetLevel removes the level of the given name.
+// Does not remove the levels of more specific names.
+func (r *LevelRegistry) UnsetLevel(name string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.levels, name)
+}
+
+// Clear removes all levels.
+func (r *LevelRegistry) Clear() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.levels = make(map[string]Level)
+}
+
+// Levels returns a copy of all registered levels.
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	levels := make(map[string]Level, len(r.levels))
+	for name, lvl := range r.levels {
+		levels[name] = lvl
+	}
+	return levels
+}
+
+// Level returns the level for the logger with the given name.
+// Returns false if neither the name nor any of its parents have a level.
+func (r *LevelRegistry) Level(name string) (Level, bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return lookupName(r.levels, name)
+}
+
+// entry returns the level for the logger with the given name, together with the name it is registered for.
+// Returns false if neither the name nor any of its parents have a level.
+func (r *LevelRegistry) entry(name string) (string, Level, bool) {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return lookupNameEntry(r.levels, name)
+}
+
+// lookupName returns the value of the given logger name or of its most specific parent.
+// The empty name matches all loggers. Returns false if neither the name nor any of its parents have a value.
+func lookupName[V any](values map[string]V, name string) (V, bool) {
+	_, v, ok := lookupNameEntry(values, name)
+	return v, ok
+}
+
+// lookupNameEntry is like lookupName, but also returns the name the value is stored for.
+func lookupNameEntry[V any](values map[string]V, name string) (string, V, bool) {
+	if len(values) > 0 {
+		for {
+			if v, ok := values[name]; ok {
+				return name, v, true
+			}
+			if name == "" {
+				break
+			}
+			name = parentLoggerName(name)
+		}
+	}
+	var zeroVal V
+	return "", zeroVal, false
+}
+
+// parentLoggerName removes the last name component.
+func parentLoggerName(name string) string {
+	idx := strings.LastIndex(name, nameSeparator)
+	if idx < 0 {
+		return ""
+	}
+	return name[:idx]
+}
@@ -0,0 +1,74 @@
+package mlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelRegistry(t *testing.T) {
+	reg := NewLevelRegistry()
+	_, ok := reg.Level("db")
+	assert.False(t, ok)
+
+	reg.SetLevel("db", DebugLevel)
+	reg.SetLevel("db.pool.conn", TraceLevel)
+
+	assertRegistryLevel(t, reg, "db", DebugLevel)
+	assertRegistryLevel(t, reg, "db.pool", DebugLevel)
+	assertRegistryLevel(t, reg, "db.pool.conn", TraceLevel)
+	assertRegistryLevel(t, reg, "db.pool.conn.x", TraceLevel)
+	_, ok = reg.Level("dbx")
+	assert.False(t, ok)
+	_, ok = reg.Level("")
+	assert.False(t, ok)
+
+	reg.SetLevel("", WarnLevel)
+	assertRegistryLevel(t, reg, "dbx", WarnLevel)
+	assertRegistryLevel(t, reg, "", WarnLevel)
+
+	reg.UnsetLevel("db")
+	assertRegistryLevel(t, reg, "db.pool", WarnLevel)
+	assert.Equal(t, map[string]Level{"": WarnLevel, "db.pool.conn": TraceLevel}, reg.Levels())
+
+	reg.Clear()
+	assert.Empty(t, reg.Levels())
+}
+
+func assertRegistryLevel(t *testing.T, reg *LevelRegistry, name string, expected Level) {
+	t.Helper()
+	lvl, ok := reg.Level(name)
+	assert.True(t, ok)
+	assert.Equal(t, expected, lvl)
+}
+
+func Test_parentLoggerName(t *testing.T) {
+	assert.Equal(t, "", parentLoggerName(""))
+	assert.Equal(t, "", parentLoggerName("a"))
+	assert.Equal(t, "a", parentLoggerName("a.b"))
+	assert.Equal(t, "a.b", parentLoggerName("a.b.c"))
+}
+
+func Test_lookupName(t *testing.T) {
+	values := map[string]int{"": 1, "db": 2, "db.pool.conn": 3}
+
+	for name, expected := range map[string]int{
+		"":                  1,
+		"http":              1,
+		"db":                2,
+		"db.pool":           2,
+		"db.pool.conn":      3,
+		"db.pool.conn.sub":  3,
+		"dbx.pool.conn.sub": 1,
+	} {
+		v, ok := lookupName(values, name)
+		assert.True(t, ok)
+		assert.Equal(t, expected, v, name)
+	}
+
+	delete(values, "")
+	_, ok := lookupName(values, "http")
+	assert.False(t, ok)
+	_, ok = lookupName(map[string]int(nil), "db")
+	assert.False(t, ok)
+}
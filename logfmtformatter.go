@@ -0,0 +1,98 @@
+package mlog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LogfmtFormatter formats entries as logfmt lines (key=value pairs).
+// Values containing spaces, quotes, equal signs or control characters are quoted and escaped.
+// Fields that clash with other keys are prefixed with "fields.".
+func LogfmtFormatter(entry *Entry) ([]byte, error) {
+	sb := &logfmtLine{keys: make(map[string]struct{})}
+	for _, k := range logfmtReservedKeys {
+		sb.keys[k] = struct{}{}
+	}
+	writeLogfmtPair(sb, "ts", entry.Time.Format(time.RFC3339Nano))
+	writeLogfmtPair(sb, "level", entry.Level.String())
+	writeLogfmtPair(sb, "name", entry.Name)
+	writeLogfmtPair(sb, "msg", entry.Message)
+	if entry.Error != nil {
+		writeLogfmtPair(sb, "error", entry.Error.Error())
+		writeLogfmtPair(sb, "error_type", entry.ErrorType())
+	}
+	if entry.Caller != nil {
+		writeLogfmtPair(sb, "caller", entry.Caller.File+":"+strconv.Itoa(entry.Caller.Line))
+	}
+	if entry.StackTrace != nil {
+		writeLogfmtPair(sb, "stacktrace", formatStackTrace(entry.StackTrace, ""))
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for k := range entry.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := k
+		if _, exists := sb.keys[logfmtKey(key)]; exists {
+			key = "fields." + key
+		}
+		writeLogfmtPair(sb, key, fmt.Sprint(entry.Fields[k]))
+	}
+
+	sb.WriteString("\n")
+	return []byte(sb.String()), nil
+}
+
+// logfmtReservedKeys are always reserved for entry properties, even if the entry doesn't contain them.
+var logfmtReservedKeys = []string{"ts", "level", "name", "msg", "error", "error_type", "caller", "stacktrace"}
+
+// logfmtLine is a logfmt line under construction.
+type logfmtLine struct {
+	strings.Builder
+	keys map[string]struct{} // keys written so far
+}
+
+func writeLogfmtPair(sb *logfmtLine, key, value string) {
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	key = logfmtKey(key)
+	sb.keys[key] = struct{}{}
+	sb.WriteString(key)
+	sb.WriteByte('=')
+	sb.WriteString(logfmtValue(value))
+}
+
+// logfmtKey replaces all characters that are not allowed within keys.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue quotes the value if necessary.
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	needsQuotes := strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0
+	if !needsQuotes {
+		return value
+	}
+	return strconv.Quote(value)
+}
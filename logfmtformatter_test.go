@@ -0,0 +1,60 @@
+package mlog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:   InfoLevel,
+		Name:    "db.pool",
+		Message: "connection \"main\" established\nretrying",
+		Fields: Fields{
+			"b":         "",
+			"a":         1,
+			"with key":  "x=y",
+			"backslash": `a\b`,
+		},
+		Error: errors.New("failure"),
+	}
+
+	data, err := LogfmtFormatter(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, `ts=2021-05-15T12:30:00Z level=info name=db.pool msg="connection \"main\" established\nretrying" `+
+		`error=failure error_type=*errors.errorString a=1 b="" backslash="a\\b" with_key="x=y"`+"\n", string(data))
+}
+
+func TestLogfmtFormatter_KeyClash(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:   InfoLevel,
+		Name:    "root",
+		Message: "msg",
+		Fields: Fields{
+			"ts":    1,
+			"level": 2,
+			"msg":   3,
+			"error": 4,
+			"other": 5,
+		},
+	}
+
+	data, err := LogfmtFormatter(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, `ts=2021-05-15T12:30:00Z level=info name=root msg=msg `+
+		`fields.error=4 fields.level=2 fields.msg=3 other=5 fields.ts=1`+"\n", string(data))
+}
+
+func Test_logfmtValue(t *testing.T) {
+	assert.Equal(t, `""`, logfmtValue(""))
+	assert.Equal(t, `abc`, logfmtValue("abc"))
+	assert.Equal(t, `"a b"`, logfmtValue("a b"))
+	assert.Equal(t, `"a\tb"`, logfmtValue("a\tb"))
+	assert.Equal(t, `"\"a\""`, logfmtValue(`"a"`))
+	assert.Equal(t, `ünïcödé`, logfmtValue("ünïcödé"))
+}
@@ -1,17 +1,46 @@
 package mlog
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
 const nameSeparator = "."
 
+// Fields contains structured key/value data attached to log messages.
+type Fields map[string]interface{}
+
 // Logger receives and processes log messages.
 type Logger interface {
 	// New returns a new sub-logger with the given name components appended
 	New(name ...string) Logger
 
+	// With returns a new logger with the given key/value pairs added to its fields.
+	// Keys are converted to strings. A missing value for the last key is reported as "!MISSING".
+	With(keyvals ...interface{}) Logger
+
+	// WithFields returns a new logger with the given fields added.
+	// Fields are inherited by sub-loggers.
+	// The new logger shares its level with this logger: SetLevel on either of them changes the level of both.
+	// The same applies to With, WithContext, WithError and V.
+	WithFields(fields Fields) Logger
+
+	// WithContext returns a new logger with the fields of all registered context values added.
+	// See RegisterContextKey.
+	WithContext(ctx context.Context) Logger
+
+	// WithError returns a new logger with the error attached to all log messages.
+	// Error arguments of log methods are attached automatically.
+	WithError(err error) Logger
+
+	// V returns a logger that only logs messages if the configured verbosity for the logger's name is at least v.
+	// Useful for porting code that uses klog-style verbosity levels.
+	// Like in go-logr, loggers derived from the returned logger, including sub-loggers, keep the verbosity.
+	V(v int) Logger
+
 	// Name returns the logger's full name
 	Name() string
 
@@ -19,7 +48,9 @@ type Logger interface {
 	Level() Level
 
 	// SetLevel changes the logger's log level.
-	// Does not modify the level of parent- or sub-loggers.
+	// Does not modify the level of parent-loggers,
+	// but affects all loggers derived via With, WithFields, WithContext, WithError and V.
+	// StdLogger also shares the level with sub-loggers of the same name, see StdLogger.New and StdLogger.SetLevel.
 	SetLevel(level Level)
 
 	// IsLevelEnabled checks if the log level of the logger would print a message with the given level.
@@ -29,6 +60,8 @@ type Logger interface {
 	// All lines written to it will be logged with the given log level.
 	WriterLevel(level Level) io.WriteCloser
 
+	// Log logs a message with the given level.
+	// Never panics or terminates the program, even for PanicLevel and FatalLevel.
 	Log(level Level, args ...interface{})
 	Logf(level Level, format string, args ...interface{})
 
@@ -46,6 +79,45 @@ type Logger interface {
 
 	Error(args ...interface{})
 	Errorf(format string, args ...interface{})
+
+	// Fatal logs a message with FatalLevel and terminates the program afterwards.
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+
+	// Panic logs a message with PanicLevel and panics with the message afterwards.
+	Panic(args ...interface{})
+	Panicf(format string, args ...interface{})
+
+	// Fn methods only call the function to create the message if the level is enabled.
+	// See Lazy for lazily computed arguments.
+
+	LogFn(level Level, fn func() string)
+	TraceFn(fn func() string)
+	DebugFn(fn func() string)
+	InfoFn(fn func() string)
+	WarnFn(fn func() string)
+	ErrorFn(fn func() string)
+
+	// Context-aware log methods add the fields of registered context values to the message.
+	// See RegisterContextKey.
+
+	LogCtx(ctx context.Context, level Level, args ...interface{})
+	LogfCtx(ctx context.Context, level Level, format string, args ...interface{})
+
+	TraceCtx(ctx context.Context, args ...interface{})
+	TracefCtx(ctx context.Context, format string, args ...interface{})
+
+	DebugCtx(ctx context.Context, args ...interface{})
+	DebugfCtx(ctx context.Context, format string, args ...interface{})
+
+	InfoCtx(ctx context.Context, args ...interface{})
+	InfofCtx(ctx context.Context, format string, args ...interface{})
+
+	WarnCtx(ctx context.Context, args ...interface{})
+	WarnfCtx(ctx context.Context, format string, args ...interface{})
+
+	ErrorCtx(ctx context.Context, args ...interface{})
+	ErrorfCtx(ctx context.Context, format string, args ...interface{})
 }
 
 func appendLoggerNameComponents(name string, newNames ...string) string {
@@ -57,3 +129,63 @@ func appendLoggerNameComponents(name string, newNames ...string) string {
 	}
 	return name + strings.Join(newNames, nameSeparator)
 }
+
+const missingValue = "!MISSING"
+
+// keyValsToFields converts alternating keys and values into fields.
+func keyValsToFields(keyvals ...interface{}) Fields {
+	fields := make(Fields, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		if i+1 < len(keyvals) {
+			fields[key] = keyvals[i+1]
+		} else {
+			fields[key] = missingValue
+		}
+	}
+	return fields
+}
+
+// mergeFields returns a new map containing the fields of both maps.
+// Fields in add overwrite existing fields in base.
+func mergeFields(base Fields, add Fields) Fields {
+	merged := make(Fields, len(base)+len(add))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range add {
+		merged[k] = v
+	}
+	return merged
+}
+
+// formatFields renders fields as space-separated key=value pairs, sorted by key.
+func formatFields(fields Fields) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(fmt.Sprint(fields[k]))
+	}
+	return sb.String()
+}
+
+// isSubLoggerName returns true if name equals parent or is a sub-logger of it.
+func isSubLoggerName(name, parent string) bool {
+	if parent == "" || name == parent {
+		return true
+	}
+	return strings.HasPrefix(name, parent+nameSeparator)
+}
@@ -14,3 +14,24 @@ func Test_appendLoggerNameComponents(t *testing.T) {
 	assert.Equal(t, "a.b.c", appendLoggerNameComponents("a.b", "c"))
 	assert.Equal(t, "a.b.c.d", appendLoggerNameComponents("a.b", "c", "d"))
 }
+
+func Test_keyValsToFields(t *testing.T) {
+	assert.Equal(t, Fields{}, keyValsToFields())
+	assert.Equal(t, Fields{"a": 1, "b": "2"}, keyValsToFields("a", 1, "b", "2"))
+	assert.Equal(t, Fields{"1": true}, keyValsToFields(1, true))
+	assert.Equal(t, Fields{"a": 1, "b": missingValue}, keyValsToFields("a", 1, "b"))
+}
+
+func Test_formatFields(t *testing.T) {
+	assert.Equal(t, "", formatFields(nil))
+	assert.Equal(t, "a=1 b=x", formatFields(Fields{"b": "x", "a": 1}))
+}
+
+func Test_isSubLoggerName(t *testing.T) {
+	assert.True(t, isSubLoggerName("", ""))
+	assert.True(t, isSubLoggerName("a", ""))
+	assert.True(t, isSubLoggerName("a", "a"))
+	assert.True(t, isSubLoggerName("a.b", "a"))
+	assert.False(t, isSubLoggerName("ab", "a"))
+	assert.False(t, isSubLoggerName("a", "a.b"))
+}
@@ -1,10 +1,13 @@
 package mlog
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/maja42/gotils"
 	"github.com/maja42/gotils/compare"
@@ -14,15 +17,24 @@ import (
 
 // MemLogger collects all log-messages instead of processing them.
 // Intended for testing purposes to easily verify that certain messages were logged.
+// Loggers created via With or WithFields share their storage with the parent.
 type MemLogger struct {
+	*memStore
+	fields Fields
+	ctx    context.Context
+}
+
+type memStore struct {
 	m    sync.Mutex
-	logs map[Level][]string
+	logs map[Level][]Entry
 }
 
 // NewMemLogger returns a new memory logger.
 func NewMemLogger() *MemLogger {
 	return &MemLogger{
-		logs: make(map[Level][]string),
+		memStore: &memStore{
+			logs: make(map[Level][]Entry),
+		},
 	}
 }
 
@@ -30,6 +42,37 @@ func (l *MemLogger) New(...string) Logger {
 	return l
 }
 
+func (l *MemLogger) With(keyvals ...interface{}) Logger {
+	return l.WithFields(keyValsToFields(keyvals...))
+}
+
+func (l *MemLogger) WithFields(fields Fields) Logger {
+	return &MemLogger{
+		memStore: l.memStore,
+		fields:   mergeFields(l.fields, fields),
+		ctx:      l.ctx,
+	}
+}
+
+func (l *MemLogger) WithContext(ctx context.Context) Logger {
+	return &MemLogger{
+		memStore: l.memStore,
+		fields:   mergeFields(l.fields, ContextFields(ctx)),
+		ctx:      ctx,
+	}
+}
+
+// WithError returns a new logger with the error attached to all log messages.
+// The error is recorded in Entry.Error instead of the fields.
+func (l *MemLogger) WithError(err error) Logger {
+	return l.WithFields(Fields{errorKey: err})
+}
+
+// V returns the logger itself, as memory loggers record all messages regardless of their verbosity.
+func (l *MemLogger) V(int) Logger {
+	return l
+}
+
 func (l *MemLogger) Name() string {
 	return ""
 }
@@ -49,19 +92,39 @@ func (l *MemLogger) WriterLevel(Level) io.WriteCloser {
 }
 
 func (l *MemLogger) Log(level Level, args ...interface{}) {
-	msg := fmt.Sprint(args...)
-
-	l.m.Lock()
-	defer l.m.Unlock()
-	l.logs[level] = append(l.logs[level], msg)
+	args = resolveLazy(args)
+	l.add(level, fmt.Sprint(args...), args)
 }
 
 func (l *MemLogger) Logf(level Level, format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
+	args = resolveLazy(args)
+	l.add(level, fmt.Sprintf(format, args...), args)
+}
+
+// add records the message. Lazy field values are resolved.
+func (l *MemLogger) add(level Level, msg string, args []interface{}) {
+	fields := resolveLazyFields(l.fields)
+	var err error
+	errorInMessage := false
+	if _, ok := fields[errorKey].(error); ok {
+		fields = mergeFields(fields, nil)
+		err = splitErrorField(fields)
+	} else if err = firstError(args); err != nil {
+		errorInMessage = true
+	}
 
 	l.m.Lock()
 	defer l.m.Unlock()
-	l.logs[level] = append(l.logs[level], msg)
+	l.logs[level] = append(l.logs[level], Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  fields,
+		Error:   err,
+		Context: l.ctx,
+
+		errorInMessage: errorInMessage,
+	})
 }
 
 func (l *MemLogger) Trace(args ...interface{}) {
@@ -104,10 +167,110 @@ func (l *MemLogger) Errorf(format string, args ...interface{}) {
 	l.Logf(ErrorLevel, format, args...)
 }
 
+// Fatal records the message with FatalLevel.
+// Does not terminate the program, allowing tests to verify fatal errors.
+func (l *MemLogger) Fatal(args ...interface{}) {
+	l.Log(FatalLevel, args...)
+}
+
+// Fatalf records the message with FatalLevel.
+// Does not terminate the program, allowing tests to verify fatal errors.
+func (l *MemLogger) Fatalf(format string, args ...interface{}) {
+	l.Logf(FatalLevel, format, args...)
+}
+
+// Panic records the message with PanicLevel and panics afterwards.
+func (l *MemLogger) Panic(args ...interface{}) {
+	args = resolveLazy(args)
+	msg := fmt.Sprint(args...)
+	l.add(PanicLevel, msg, args)
+	panic(msg)
+}
+
+// Panicf records the message with PanicLevel and panics afterwards.
+func (l *MemLogger) Panicf(format string, args ...interface{}) {
+	args = resolveLazy(args)
+	msg := fmt.Sprintf(format, args...)
+	l.add(PanicLevel, msg, args)
+	panic(msg)
+}
+
+func (l *MemLogger) LogFn(level Level, fn func() string) {
+	l.add(level, fn(), nil)
+}
+
+func (l *MemLogger) TraceFn(fn func() string) {
+	l.LogFn(TraceLevel, fn)
+}
+
+func (l *MemLogger) DebugFn(fn func() string) {
+	l.LogFn(DebugLevel, fn)
+}
+
+func (l *MemLogger) InfoFn(fn func() string) {
+	l.LogFn(InfoLevel, fn)
+}
+
+func (l *MemLogger) WarnFn(fn func() string) {
+	l.LogFn(WarnLevel, fn)
+}
+
+func (l *MemLogger) ErrorFn(fn func() string) {
+	l.LogFn(ErrorLevel, fn)
+}
+
+func (l *MemLogger) LogCtx(ctx context.Context, level Level, args ...interface{}) {
+	l.WithContext(ctx).Log(level, args...)
+}
+
+func (l *MemLogger) LogfCtx(ctx context.Context, level Level, format string, args ...interface{}) {
+	l.WithContext(ctx).Logf(level, format, args...)
+}
+
+func (l *MemLogger) TraceCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, TraceLevel, args...)
+}
+
+func (l *MemLogger) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, TraceLevel, format, args...)
+}
+
+func (l *MemLogger) DebugCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, DebugLevel, args...)
+}
+
+func (l *MemLogger) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, DebugLevel, format, args...)
+}
+
+func (l *MemLogger) InfoCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, InfoLevel, args...)
+}
+
+func (l *MemLogger) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, InfoLevel, format, args...)
+}
+
+func (l *MemLogger) WarnCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, WarnLevel, args...)
+}
+
+func (l *MemLogger) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, WarnLevel, format, args...)
+}
+
+func (l *MemLogger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, ErrorLevel, args...)
+}
+
+func (l *MemLogger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, ErrorLevel, format, args...)
+}
+
 func (l *MemLogger) Clear() {
 	l.m.Lock()
 	defer l.m.Unlock()
-	l.logs = make(map[Level][]string)
+	l.logs = make(map[Level][]Entry)
 }
 
 func (l *MemLogger) LogCount() int {
@@ -125,11 +288,56 @@ func (l *MemLogger) Logs(level Level) []string {
 	l.m.Lock()
 	defer l.m.Unlock()
 
+	return l.messages(level)
+}
+
+// messages returns all log messages of a given level.
+// The caller must hold the lock.
+func (l *MemLogger) messages(level Level) []string {
 	logs := make([]string, len(l.logs[level]))
-	copy(logs, l.logs[level])
+	for i, entry := range l.logs[level] {
+		logs[i] = entry.Message
+	}
 	return logs
 }
 
+// Entries returns a copy of all log entries of a given level.
+func (l *MemLogger) Entries(level Level) []Entry {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	entries := make([]Entry, len(l.logs[level]))
+	copy(entries, l.logs[level])
+	return entries
+}
+
+// Fields returns the fields of all log messages of a given level.
+func (l *MemLogger) Fields(level Level) []Fields {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	fields := make([]Fields, len(l.logs[level]))
+	for i, entry := range l.logs[level] {
+		fields[i] = entry.Fields
+	}
+	return fields
+}
+
+// Errors returns the errors attached to log messages of a given level.
+// Messages without an error are skipped.
+func (l *MemLogger) Errors(level Level) []error {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	var errs []error
+	for _, entry := range l.logs[level] {
+		if entry.Error != nil {
+			errs = append(errs, entry.Error)
+		}
+	}
+	return errs
+}
+
 // TraceLogs returns a copy of all trace logs.
 func (l *MemLogger) TraceLogs() []string {
 	return l.Logs(TraceLevel)
@@ -155,6 +363,16 @@ func (l *MemLogger) ErrorLogs() []string {
 	return l.Logs(ErrorLevel)
 }
 
+// FatalLogs returns a copy of all fatal logs.
+func (l *MemLogger) FatalLogs() []string {
+	return l.Logs(FatalLevel)
+}
+
+// PanicLogs returns a copy of all panic logs.
+func (l *MemLogger) PanicLogs() []string {
+	return l.Logs(PanicLevel)
+}
+
 // AssertNoLogs verifies that there are no log messages with the given level(s).
 // Requires at least one level. Pass AllLevels to verify that no log-statements were made.
 func (l *MemLogger) AssertNoLogs(t testutil.TestingT, levels ...Level) bool {
@@ -169,7 +387,7 @@ func (l *MemLogger) AssertNoLogs(t testutil.TestingT, levels ...Level) bool {
 	defer l.m.Unlock()
 
 	for _, level := range levels {
-		logs := l.logs[level]
+		logs := l.messages(level)
 		if len(logs) == 0 {
 			continue
 		}
@@ -193,6 +411,21 @@ func (l *MemLogger) AssertNoWarnings(t testutil.TestingT) bool {
 	return l.AssertNoLogs(t, ErrorLevel)
 }
 
+// AssertLoggedError verifies that an error matching the target was attached to at least one log message.
+// Errors are matched using errors.Is.
+func (l *MemLogger) AssertLoggedError(t testutil.TestingT, level Level, target error) bool {
+	t.Helper()
+
+	errs := l.Errors(level)
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	t.Errorf("Expected %s log with error %q, but got %d error(s): %v", level, target, len(errs), errs)
+	return false
+}
+
 // AssertAllMessages verifies that the given messages were logged.
 // Reports an error if additional messages were logged, or some expected messages are missing.
 // The message order is ignored.
@@ -202,7 +435,7 @@ func (l *MemLogger) AssertAllMessages(t testutil.TestingT, level Level, messages
 	defer l.m.Unlock()
 
 	msg := fmt.Sprintf("Unexpected %s log messages", level)
-	return testutil.AssertElementsMatch(t, msg, messages, l.logs[level], compare.String)
+	return testutil.AssertElementsMatch(t, msg, messages, l.messages(level), compare.String)
 }
 
 // AssertAllSubMessages verifies that the given messages were logged.
@@ -215,7 +448,7 @@ func (l *MemLogger) AssertAllSubMessages(t testutil.TestingT, level Level, subMe
 	defer l.m.Unlock()
 
 	msg := fmt.Sprintf("Unexpected %s log messages", level)
-	return testutil.AssertElementsMatch(t, msg, subMessages, l.logs[level], compare.SubString)
+	return testutil.AssertElementsMatch(t, msg, subMessages, l.messages(level), compare.SubString)
 }
 
 // AssertAnyMessage verifies that the given message was logged at least once.
@@ -224,7 +457,7 @@ func (l *MemLogger) AssertAnyMessage(t testutil.TestingT, level Level, message s
 	l.m.Lock()
 	defer l.m.Unlock()
 
-	return assert.Contains(t, l.logs[level], message)
+	return assert.Contains(t, l.messages(level), message)
 }
 
 // AssertAnySubMessage verifies that the given subMessage was a sub-string of at least one log message.
@@ -233,11 +466,12 @@ func (l *MemLogger) AssertAnySubMessage(t testutil.TestingT, level Level, subMes
 	l.m.Lock()
 	defer l.m.Unlock()
 
-	for _, msg := range l.logs[level] {
+	logs := l.messages(level)
+	for _, msg := range logs {
 		if strings.Contains(msg, subMessage) {
 			return true
 		}
 	}
-	t.Errorf("%#v does not contain message with %q", l.logs[level], subMessage)
+	t.Errorf("%#v does not contain message with %q", logs, subMessage)
 	return false
 }
@@ -1,6 +1,10 @@
 package mlog
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -67,3 +71,69 @@ func TestMemLogger_Assertions(t *testing.T) {
 	logger.AssertAnySubMessage(t, ErrorLevel, "e")
 	logger.AssertNoLogs(t, WarnLevel, InfoLevel, DebugLevel, TraceLevel)
 }
+
+func TestMemLogger_WithFields(t *testing.T) {
+	logger := NewMemLogger()
+
+	sub := logger.With("request", 42)
+	sub.Info("with fields")
+	sub.WithFields(Fields{"user": "bob"}).Info("more fields")
+	logger.Info("no fields")
+
+	logger.AssertAllMessages(t, InfoLevel, "with fields", "more fields", "no fields")
+	assert.Equal(t, []Fields{
+		{"request": 42},
+		{"request": 42, "user": "bob"},
+		nil,
+	}, logger.Fields(InfoLevel))
+}
+
+func TestMemLogger_Errors(t *testing.T) {
+	logger := NewMemLogger()
+
+	err := fmt.Errorf("wrapped: %w", io.EOF)
+	logger.WithError(err).Warn("msg")
+	logger.Error("failed: ", err)
+	logger.With("k", "v").Error("msg")
+
+	assert.Equal(t, []error{err}, logger.Errors(WarnLevel))
+	assert.Equal(t, []error{err}, logger.Errors(ErrorLevel))
+	assert.Equal(t, []Fields{{}}, logger.Fields(WarnLevel))
+	logger.AssertLoggedError(t, WarnLevel, io.EOF)
+	logger.AssertLoggedError(t, ErrorLevel, err)
+}
+
+func TestMemLogger_FatalPanic(t *testing.T) {
+	logger := NewMemLogger()
+
+	logger.Fatalf("fatal %d", 1)
+	assert.PanicsWithValue(t, "panic", func() {
+		logger.Panic("panic")
+	})
+
+	assert.Equal(t, []string{"fatal 1"}, logger.FatalLogs())
+	assert.Equal(t, []string{"panic"}, logger.PanicLogs())
+
+	err := errors.New("failure")
+	logger.Fatal("fatal: ", err)
+	assert.PanicsWithValue(t, "panic: failure", func() {
+		logger.Panic("panic: ", err)
+	})
+	assert.Equal(t, []error{err}, logger.Errors(FatalLevel))
+	assert.Equal(t, []error{err}, logger.Errors(PanicLevel))
+}
+
+func TestSetExitFunc(t *testing.T) {
+	var exitCodes []int
+	SetExitFunc(func(code int) {
+		exitCodes = append(exitCodes, code)
+	})
+	t.Cleanup(func() {
+		SetExitFunc(nil)
+	})
+
+	NOPLogger.Fatal("fatal")
+	NOPLogger.Fatalf("fatal %d", 1)
+	FromSlog(slog.New(slog.NewTextHandler(io.Discard, nil))).Fatal("fatal")
+	assert.Equal(t, []int{1, 1, 1}, exitCodes)
+}
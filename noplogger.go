@@ -1,6 +1,37 @@
 package mlog
 
-import "io"
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// exitFunc contains the exitHolder used by loggers that are not created via the LoggerBuilder.
+var exitFunc atomic.Value
+
+// exitHolder allows storing nil functions within an atomic.Value.
+type exitHolder struct {
+	exit func(code int)
+}
+
+// SetExitFunc replaces the function that terminates the program on fatal messages, which defaults to os.Exit.
+// Affects NOPLogger and loggers returned by FromSlog. Allows tests to intercept Logger.Fatal.
+// Fatal returns if the exit function returns. Use LoggerBuilder.WithExitFunc for standard loggers.
+func SetExitFunc(exit func(code int)) {
+	exitFunc.Store(exitHolder{exit})
+}
+
+// exit terminates the program using the function configured via SetExitFunc.
+func exit(code int) {
+	holder, ok := exitFunc.Load().(exitHolder)
+	if !ok || holder.exit == nil {
+		os.Exit(code)
+		return
+	}
+	holder.exit(code)
+}
 
 type nopLogger struct {
 }
@@ -13,6 +44,46 @@ func (nopLogger) New(...string) Logger {
 	return nopLogger{}
 }
 
+func (nopLogger) With(...interface{}) Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) WithFields(Fields) Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) WithContext(context.Context) Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) WithError(error) Logger {
+	return nopLogger{}
+}
+
+// Fatal terminates the program without logging. See SetExitFunc.
+func (nopLogger) Fatal(...interface{}) {
+	exit(1)
+}
+
+// Fatalf terminates the program without logging. See SetExitFunc.
+func (nopLogger) Fatalf(string, ...interface{}) {
+	exit(1)
+}
+
+// Panic panics with the message without logging.
+func (nopLogger) Panic(args ...interface{}) {
+	panic(fmt.Sprint(args...))
+}
+
+// Panicf panics with the message without logging.
+func (nopLogger) Panicf(format string, args ...interface{}) {
+	panic(fmt.Sprintf(format, args...))
+}
+
+func (nopLogger) V(int) Logger {
+	return nopLogger{}
+}
+
 func (nopLogger) Name() string {
 	return ""
 }
@@ -59,3 +130,29 @@ func (nopLogger) Warnf(string, ...interface{}) {}
 
 func (nopLogger) Error(...interface{})          {}
 func (nopLogger) Errorf(string, ...interface{}) {}
+
+func (nopLogger) LogCtx(context.Context, Level, ...interface{})          {}
+func (nopLogger) LogfCtx(context.Context, Level, string, ...interface{}) {}
+
+func (nopLogger) TraceCtx(context.Context, ...interface{})          {}
+func (nopLogger) TracefCtx(context.Context, string, ...interface{}) {}
+
+func (nopLogger) DebugCtx(context.Context, ...interface{})          {}
+func (nopLogger) DebugfCtx(context.Context, string, ...interface{}) {}
+
+func (nopLogger) InfoCtx(context.Context, ...interface{})          {}
+func (nopLogger) InfofCtx(context.Context, string, ...interface{}) {}
+
+func (nopLogger) WarnCtx(context.Context, ...interface{})          {}
+func (nopLogger) WarnfCtx(context.Context, string, ...interface{}) {}
+
+func (nopLogger) ErrorCtx(context.Context, ...interface{})          {}
+func (nopLogger) ErrorfCtx(context.Context, string, ...interface{}) {}
+
+func (nopLogger) LogFn(Level, func() string) {}
+
+func (nopLogger) TraceFn(func() string) {}
+func (nopLogger) DebugFn(func() string) {}
+func (nopLogger) InfoFn(func() string)  {}
+func (nopLogger) WarnFn(func() string)  {}
+func (nopLogger) ErrorFn(func() string) {}
@@ -0,0 +1,155 @@
+// Package otel correlates log messages with OpenTelemetry traces.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/maja42/mlog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field keys of the trace correlation fields.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// ContextFields returns the trace and span id of the span stored within the context.
+// Returns nil if the context does not contain a valid span.
+func ContextFields(ctx context.Context) mlog.Fields {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+	return mlog.Fields{
+		TraceIDKey: spanCtx.TraceID().String(),
+		SpanIDKey:  spanCtx.SpanID().String(),
+	}
+}
+
+// Register adds the trace and span id to all messages logged via context-aware log methods,
+// like mlog.Logger.InfoCtx.
+// Returns a function that removes the ids again.
+func Register() (unregister func()) {
+	atomic.AddInt32(&registered, 1)
+	unregisterExtractor := mlog.RegisterContextExtractor(ContextFields)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unregisterExtractor()
+			atomic.AddInt32(&registered, -1)
+		})
+	}
+}
+
+// registered is the number of active Register calls; accessed atomically.
+var registered int32
+
+// Options configure the Logger.
+type Options struct {
+	// SpanEvents adds warnings and errors as events to the span stored within the context.
+	SpanEvents bool
+}
+
+// Logger logs messages with the trace and span id of the context's span.
+type Logger struct {
+	logger mlog.Logger
+	opts   Options
+}
+
+// New returns a Logger that logs into the given logger.
+func New(logger mlog.Logger, opts Options) *Logger {
+	return &Logger{
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// Logger returns the underlying logger.
+func (l *Logger) Logger() mlog.Logger {
+	return l.logger
+}
+
+func (l *Logger) Log(ctx context.Context, level mlog.Level, args ...interface{}) {
+	if l.logger.IsLevelEnabled(level) || l.opts.SpanEvents {
+		l.log(ctx, level, fmt.Sprint(args...))
+	}
+}
+
+func (l *Logger) Logf(ctx context.Context, level mlog.Level, format string, args ...interface{}) {
+	if l.logger.IsLevelEnabled(level) || l.opts.SpanEvents {
+		l.log(ctx, level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *Logger) log(ctx context.Context, level mlog.Level, msg string) {
+	if l.opts.SpanEvents && !level.LessSevereThan(mlog.WarnLevel) {
+		addSpanEvent(ctx, level, l.logger.Name(), msg)
+	}
+	logger := l.logger
+	if atomic.LoadInt32(&registered) == 0 {
+		// the ids are not added by the context-aware log method
+		logger = logger.WithFields(ContextFields(ctx))
+	}
+	logger.LogCtx(ctx, level, msg)
+}
+
+// addSpanEvent adds the message as event to the recording span of the context.
+func addSpanEvent(ctx context.Context, level mlog.Level, name, msg string) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", level.String()),
+		attribute.String("log.message", msg),
+	}
+	if name != "" {
+		attrs = append(attrs, attribute.String("log.logger", name))
+	}
+	span.AddEvent("log", trace.WithAttributes(attrs...))
+}
+
+func (l *Logger) Trace(ctx context.Context, args ...interface{}) {
+	l.Log(ctx, mlog.TraceLevel, args...)
+}
+
+func (l *Logger) Tracef(ctx context.Context, format string, args ...interface{}) {
+	l.Logf(ctx, mlog.TraceLevel, format, args...)
+}
+
+func (l *Logger) Debug(ctx context.Context, args ...interface{}) {
+	l.Log(ctx, mlog.DebugLevel, args...)
+}
+
+func (l *Logger) Debugf(ctx context.Context, format string, args ...interface{}) {
+	l.Logf(ctx, mlog.DebugLevel, format, args...)
+}
+
+func (l *Logger) Info(ctx context.Context, args ...interface{}) {
+	l.Log(ctx, mlog.InfoLevel, args...)
+}
+
+func (l *Logger) Infof(ctx context.Context, format string, args ...interface{}) {
+	l.Logf(ctx, mlog.InfoLevel, format, args...)
+}
+
+func (l *Logger) Warn(ctx context.Context, args ...interface{}) {
+	l.Log(ctx, mlog.WarnLevel, args...)
+}
+
+func (l *Logger) Warnf(ctx context.Context, format string, args ...interface{}) {
+	l.Logf(ctx, mlog.WarnLevel, format, args...)
+}
+
+func (l *Logger) Error(ctx context.Context, args ...interface{}) {
+	l.Log(ctx, mlog.ErrorLevel, args...)
+}
+
+func (l *Logger) Errorf(ctx context.Context, format string, args ...interface{}) {
+	l.Logf(ctx, mlog.ErrorLevel, format, args...)
+}
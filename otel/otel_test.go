@@ -0,0 +1,91 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/maja42/mlog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracer() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return provider, exporter
+}
+
+func TestContextFields(t *testing.T) {
+	assert.Nil(t, ContextFields(context.Background()))
+
+	provider, _ := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	assert.Equal(t, mlog.Fields{
+		TraceIDKey: span.SpanContext().TraceID().String(),
+		SpanIDKey:  span.SpanContext().SpanID().String(),
+	}, ContextFields(ctx))
+}
+
+func TestLogger(t *testing.T) {
+	provider, exporter := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+
+	mem := mlog.NewMemLogger()
+	logger := New(mem, Options{})
+	logger.Info(ctx, "info")
+	logger.Warnf(ctx, "warn %d", 1)
+	logger.Info(context.Background(), "no span")
+	span.End()
+
+	ids := ContextFields(ctx)
+	mem.AssertAllMessages(t, mlog.InfoLevel, "info", "no span")
+	assert.Equal(t, []mlog.Fields{ids, {}}, mem.Fields(mlog.InfoLevel))
+	assert.Equal(t, []mlog.Fields{ids}, mem.Fields(mlog.WarnLevel))
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Empty(t, spans[0].Events)
+}
+
+func TestLogger_SpanEvents(t *testing.T) {
+	provider, exporter := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+
+	logger := New(mlog.NOPLogger, Options{SpanEvents: true})
+	logger.Info(ctx, "info")
+	logger.Warn(ctx, "warn")
+	logger.Errorf(ctx, "error %d", 1)
+	span.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	events := spans[0].Events
+	assert.Len(t, events, 2)
+	assert.Equal(t, "log", events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("log.severity", "warn"),
+		attribute.String("log.message", "warn"),
+	}, events[0].Attributes)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("log.severity", "error"),
+		attribute.String("log.message", "error 1"),
+	}, events[1].Attributes)
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(Register())
+
+	provider, _ := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	mem := mlog.NewMemLogger()
+	mem.InfoCtx(ctx, "msg")
+	New(mem, Options{}).Warn(ctx, "msg")
+	assert.Equal(t, []mlog.Fields{ContextFields(ctx)}, mem.Fields(mlog.InfoLevel))
+	assert.Equal(t, []mlog.Fields{ContextFields(ctx)}, mem.Fields(mlog.WarnLevel))
+}
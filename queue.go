@@ -0,0 +1,196 @@
+package mlog
+
+import (
+	"context"
+	"sync"
+)
+
+// OverflowPolicy defines how asynchronous components behave if their queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until there is space within the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards new items if the queue is full.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued item to make space for new ones.
+	OverflowDropOldest
+)
+
+// boundedQueue is a fixed-size FIFO ring buffer.
+type boundedQueue struct {
+	m      sync.Mutex
+	cond   *sync.Cond // signalled on any state change
+	items  []interface{}
+	head   int
+	count  int
+	policy OverflowPolicy
+
+	inFlight int // items that were removed from the queue but are still being processed
+	dropped  uint64
+	closed   bool
+
+	notify chan struct{} // receives a signal when items are added or the queue is closed
+}
+
+func newBoundedQueue(size int, policy OverflowPolicy) *boundedQueue {
+	q := &boundedQueue{
+		items:  make([]interface{}, size),
+		policy: policy,
+		notify: make(chan struct{}, 1),
+	}
+	q.cond = sync.NewCond(&q.m)
+	return q
+}
+
+// push adds an item to the queue.
+// Returns false if the item was discarded.
+func (q *boundedQueue) push(item interface{}) bool {
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	if q.policy == OverflowBlock {
+		for q.count == len(q.items) && !q.closed {
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		q.dropped++
+		return false
+	}
+
+	if q.count == len(q.items) {
+		q.dropped++
+		if q.policy == OverflowDropNewest {
+			return false
+		}
+		// drop oldest
+		q.items[q.head] = nil
+		q.head = (q.head + 1) % len(q.items)
+		q.count--
+	}
+
+	q.items[(q.head+q.count)%len(q.items)] = item
+	q.count++
+	q.cond.Broadcast()
+	q.signal()
+	return true
+}
+
+// signal notifies listeners of the notify channel without blocking.
+// The caller must hold the lock.
+func (q *boundedQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes up to max items from the queue.
+// Blocks until at least one item is available.
+// Returns nil if the queue was closed and all items were removed.
+// The caller must call done after processing the items.
+func (q *boundedQueue) pop(max int) []interface{} {
+	q.m.Lock()
+	defer q.m.Unlock()
+
+	for q.count == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	return q.take(max)
+}
+
+// tryPop removes up to max items from the queue without blocking.
+// Returns nil if the queue is empty.
+// The caller must call done after processing the items.
+func (q *boundedQueue) tryPop(max int) []interface{} {
+	q.m.Lock()
+	defer q.m.Unlock()
+	return q.take(max)
+}
+
+// take removes up to max items from the queue.
+// The caller must hold the lock.
+func (q *boundedQueue) take(max int) []interface{} {
+	if q.count == 0 {
+		return nil
+	}
+
+	n := q.count
+	if n > max {
+		n = max
+	}
+	items := make([]interface{}, n)
+	for i := range items {
+		items[i] = q.items[q.head]
+		q.items[q.head] = nil
+		q.head = (q.head + 1) % len(q.items)
+	}
+	q.count -= n
+	q.inFlight += n
+	q.cond.Broadcast()
+	return items
+}
+
+// done marks previously popped items as processed.
+func (q *boundedQueue) done(n int) {
+	q.m.Lock()
+	defer q.m.Unlock()
+	q.inFlight -= n
+	q.cond.Broadcast()
+}
+
+// wait blocks until all items were processed.
+func (q *boundedQueue) wait(ctx context.Context) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			q.m.Lock()
+			q.cond.Broadcast()
+			q.m.Unlock()
+		case <-stop:
+		}
+	}()
+
+	q.m.Lock()
+	defer q.m.Unlock()
+	for q.count > 0 || q.inFlight > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// close rejects new items. Remaining items can still be popped.
+func (q *boundedQueue) close() {
+	q.m.Lock()
+	defer q.m.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.signal()
+}
+
+// wake sends a signal to the notify channel.
+func (q *boundedQueue) wake() {
+	q.m.Lock()
+	defer q.m.Unlock()
+	q.signal()
+}
+
+// isClosed returns true if the queue was closed.
+func (q *boundedQueue) isClosed() bool {
+	q.m.Lock()
+	defer q.m.Unlock()
+	return q.closed
+}
+
+// droppedCount returns the number of discarded items.
+func (q *boundedQueue) droppedCount() uint64 {
+	q.m.Lock()
+	defer q.m.Unlock()
+	return q.dropped
+}
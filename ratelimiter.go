@@ -0,0 +1,124 @@
+package mlog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the number of messages per logger using a token bucket.
+// Each logger name has its own bucket. Messages are dropped if the bucket is empty.
+// Once messages are logged again, the number of dropped messages is logged beforehand.
+// Pending numbers of dropped messages are logged by Close.
+type RateLimiter struct {
+	rate  float64 // tokens per second
+	burst int
+	now   func() time.Time
+
+	m       sync.Mutex
+	buckets map[string]*tokenBucket // by logger name
+	dropped uint64
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	dropped int        // since the last logged message
+	logger  *StdLogger // that logged the last dropped message
+	level   Level      // of the last dropped message
+}
+
+// NewRateLimiter returns a rate limiter that allows rate messages per second and logger,
+// with bursts of up to burst messages. See LoggerBuilder.WithRateLimiter.
+// Panics if the rate is not positive.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if !(rate > 0) {
+		panic(fmt.Sprintf("invalid rate limit %v: must be positive", rate))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow returns true if the message should be logged.
+func (r *RateLimiter) allow(logger *StdLogger, level Level) bool {
+	now := r.now()
+
+	r.m.Lock()
+	bucket, ok := r.buckets[logger.name]
+	if !ok {
+		bucket = &tokenBucket{
+			tokens:  float64(r.burst),
+			updated: now,
+		}
+		r.buckets[logger.name] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.updated).Seconds() * r.rate
+	if bucket.tokens > float64(r.burst) {
+		bucket.tokens = float64(r.burst)
+	}
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		bucket.dropped++
+		bucket.logger = logger
+		bucket.level = level
+		r.dropped++
+		r.m.Unlock()
+		return false
+	}
+	bucket.tokens--
+	dropped := bucket.dropped
+	bucket.dropped = 0
+	bucket.logger = nil
+	r.m.Unlock()
+
+	if dropped > 0 {
+		logger.write(level, nil, fmt.Sprintf("%d messages dropped due to rate limiting", dropped))
+	}
+	return true
+}
+
+// Dropped returns the total number of dropped messages.
+func (r *RateLimiter) Dropped() uint64 {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.dropped
+}
+
+// Close logs the numbers of messages that were dropped since the last logged message of each logger.
+func (r *RateLimiter) Close() error {
+	type pending struct {
+		logger  *StdLogger
+		level   Level
+		dropped int
+	}
+	var drops []pending
+
+	r.m.Lock()
+	for _, bucket := range r.buckets {
+		if bucket.dropped == 0 {
+			continue
+		}
+		drops = append(drops, pending{bucket.logger, bucket.level, bucket.dropped})
+		bucket.dropped = 0
+		bucket.logger = nil
+	}
+	r.m.Unlock()
+
+	sort.Slice(drops, func(i, j int) bool {
+		return drops[i].logger.name < drops[j].logger.name
+	})
+	for _, d := range drops {
+		d.logger.write(d.level, nil, fmt.Sprintf("%d messages dropped due to rate limiting", d.dropped))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package mlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2, 3)
+	limiter.now = func() time.Time { return now }
+
+	buf := &bytes.Buffer{}
+	root := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithEntryFormatter(func(entry *Entry) ([]byte, error) {
+			return []byte(entry.Name + " " + entry.Message + "\n"), nil
+		}).
+		WithRateLimiter("root.db", limiter).
+		Create()
+	db := root.New("db")
+	pool := db.New("pool")
+
+	for i := 0; i < 5; i++ {
+		db.Errorf("db %d", i)
+		pool.Errorf("pool %d", i)
+		root.Errorf("root %d", i)
+	}
+	assert.Equal(t, uint64(4), limiter.Dropped())
+
+	now = now.Add(time.Second) // refills two tokens
+	db.Error("db 5")
+	db.Error("db 6")
+	db.Error("db 7")
+
+	assert.Equal(t, "root.db db 0\nroot.db.pool pool 0\nroot root 0\n"+
+		"root.db db 1\nroot.db.pool pool 1\nroot root 1\n"+
+		"root.db db 2\nroot.db.pool pool 2\nroot root 2\n"+
+		"root root 3\nroot root 4\n"+
+		"root.db 2 messages dropped due to rate limiting\nroot.db db 5\nroot.db db 6\n", buf.String())
+	assert.Equal(t, uint64(5), limiter.Dropped())
+
+	buf.Reset()
+	assert.NoError(t, root.(*StdLogger).Close())
+	assert.Equal(t, "root.db 1 messages dropped due to rate limiting\n"+
+		"root.db.pool 2 messages dropped due to rate limiting\n", buf.String())
+}
+
+func TestNewRateLimiter_InvalidRate(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRateLimiter(0, 1)
+	})
+	assert.Panics(t, func() {
+		NewRateLimiter(-1, 1)
+	})
+}
@@ -0,0 +1,264 @@
+package mlog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SamplingOptions configures a Sampler.
+// Zero values are replaced by sensible defaults.
+type SamplingOptions struct {
+	// Interval is the duration after which the counters are reset. Defaults to one second.
+	Interval time.Duration
+	// First is the number of identical messages that are logged per interval. Defaults to 100.
+	First int
+	// Thereafter defines that every Mth identical message is logged after the first ones.
+	// If zero, all further messages are dropped until the interval ends.
+	Thereafter int
+	// Levels contains the sampled levels. Defaults to all levels.
+	// Messages with PanicLevel or FatalLevel are never sampled.
+	Levels []Level
+	// PerLevel overrides First and Thereafter for individual levels.
+	// Levels within PerLevel are sampled even if they are not contained in Levels.
+	PerLevel map[Level]SamplingRate
+	// Summary periodically logs the number of suppressed messages, once per interval.
+	Summary bool
+}
+
+// SamplingRate defines how many identical messages are logged per interval.
+type SamplingRate struct {
+	// First is the number of identical messages that are logged per interval. Defaults to 100.
+	First int
+	// Thereafter defines that every Mth identical message is logged after the first ones.
+	// If zero, all further messages are dropped until the interval ends.
+	Thereafter int
+}
+
+// Sampler limits the number of identical messages per interval, to prevent flooding the output from hot paths.
+// Messages are identical if they have the same level, logger name and template.
+// The template is the format string of formatted log methods, like Debugf, and the message otherwise.
+type Sampler struct {
+	opts SamplingOptions
+	now  func() time.Time
+
+	m         sync.Mutex
+	counters  map[sampleKey]*sampleCounter
+	lastPrune time.Time
+	dropped   map[Level]uint64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// sampleKey identifies identical messages.
+type sampleKey struct {
+	name     string
+	level    Level
+	template string
+}
+
+type sampleCounter struct {
+	windowStart time.Time
+	count       int
+
+	// for summaries:
+	suppressed int
+	logger     *StdLogger
+}
+
+// NewSampler returns a new sampler. See LoggerBuilder.WithSampler.
+// If summaries are enabled, the sampler must be closed to stop its goroutine.
+func NewSampler(opts SamplingOptions) *Sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.First <= 0 {
+		opts.First = 100
+	}
+	perLevel := make(map[Level]SamplingRate, len(opts.PerLevel))
+	for lvl, rate := range opts.PerLevel {
+		if rate.First <= 0 {
+			rate.First = 100
+		}
+		perLevel[lvl] = rate
+	}
+	opts.PerLevel = perLevel
+
+	s := &Sampler{
+		opts:     opts,
+		now:      time.Now,
+		counters: make(map[sampleKey]*sampleCounter),
+		dropped:  make(map[Level]uint64),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if opts.Summary {
+		go s.summarize()
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+// rate returns the sampling rate of messages with the given level.
+// Returns false if the level is not sampled.
+func (s *Sampler) rate(level Level) (SamplingRate, bool) {
+	if level == PanicLevel || level == FatalLevel {
+		return SamplingRate{}, false
+	}
+	if rate, ok := s.opts.PerLevel[level]; ok {
+		return rate, true
+	}
+	rate := SamplingRate{
+		First:      s.opts.First,
+		Thereafter: s.opts.Thereafter,
+	}
+	if s.opts.Levels == nil {
+		return rate, true
+	}
+	for _, lvl := range s.opts.Levels {
+		if lvl == level {
+			return rate, true
+		}
+	}
+	return SamplingRate{}, false
+}
+
+// allow returns true if the message should be logged.
+func (s *Sampler) allow(logger *StdLogger, level Level, template string) bool {
+	rate, ok := s.rate(level)
+	if !ok {
+		return true
+	}
+	key := sampleKey{
+		name:     logger.name,
+		level:    level,
+		template: template,
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &sampleCounter{windowStart: now}
+		s.counters[key] = counter
+	}
+	if now.Sub(counter.windowStart) >= s.opts.Interval {
+		counter.windowStart = now
+		counter.count = 0
+	}
+	counter.count++
+	n := counter.count - rate.First
+	if n <= 0 || (rate.Thereafter > 0 && n%rate.Thereafter == 0) {
+		return true
+	}
+
+	if s.opts.Summary {
+		counter.suppressed++
+		counter.logger = logger
+	}
+	s.dropped[level]++
+	return false
+}
+
+// prune removes counters of expired intervals without pending summaries, once per interval.
+// Must be called with s.m locked.
+func (s *Sampler) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < s.opts.Interval {
+		return
+	}
+	s.lastPrune = now
+	for key, counter := range s.counters {
+		if counter.suppressed == 0 && now.Sub(counter.windowStart) >= s.opts.Interval {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// Dropped returns the number of dropped messages with the given levels.
+// Returns the total number of dropped messages if no levels are provided.
+func (s *Sampler) Dropped(levels ...Level) uint64 {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var cnt uint64
+	if len(levels) == 0 {
+		for _, c := range s.dropped {
+			cnt += c
+		}
+		return cnt
+	}
+	for _, lvl := range levels {
+		cnt += s.dropped[lvl]
+	}
+	return cnt
+}
+
+// summarize periodically logs the number of suppressed messages until the sampler is closed.
+func (s *Sampler) summarize() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.logSummaries()
+		case <-s.stop:
+			s.logSummaries()
+			return
+		}
+	}
+}
+
+// logSummaries logs the number of messages suppressed since the last summary.
+// Summaries are not sampled.
+func (s *Sampler) logSummaries() {
+	type summary struct {
+		key        sampleKey
+		suppressed int
+		logger     *StdLogger
+	}
+	var summaries []summary
+
+	s.m.Lock()
+	for key, counter := range s.counters {
+		if counter.suppressed == 0 {
+			continue
+		}
+		summaries = append(summaries, summary{key, counter.suppressed, counter.logger})
+		counter.suppressed = 0
+		counter.logger = nil
+	}
+	s.m.Unlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i].key, summaries[j].key
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		return a.template < b.template
+	})
+	for _, sum := range summaries {
+		sum.logger.WithFields(Fields{"sampled_message": sum.key.template}).(*StdLogger).
+			write(sum.key.level, nil, fmt.Sprintf("%d messages suppressed", sum.suppressed))
+	}
+}
+
+// Close stops logging summaries. Logs the final summary if summaries are enabled.
+func (s *Sampler) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+	return nil
+}
@@ -0,0 +1,159 @@
+package mlog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC)
+	sampler := NewSampler(SamplingOptions{
+		First:      2,
+		Thereafter: 3,
+		Levels:     []Level{DebugLevel},
+	})
+	sampler.now = func() time.Time { return now }
+	root, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithLevel(TraceLevel).WithSampler("root.hot", sampler)
+	})
+	hot := root.New("hot")
+
+	for i := 0; i < 10; i++ {
+		hot.Debugf("msg %d", i)
+		hot.Infof("info %d", i)
+		root.Debugf("root %d", i)
+	}
+	assert.Equal(t, uint64(6), sampler.Dropped())
+	assert.Equal(t, uint64(6), sampler.Dropped(DebugLevel))
+	assert.Equal(t, uint64(0), sampler.Dropped(InfoLevel))
+
+	var hotDebug []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.HasPrefix(line, "debug root.hot") {
+			hotDebug = append(hotDebug, line)
+		}
+	}
+	assert.Equal(t, []string{
+		"debug root.hot msg 0",
+		"debug root.hot msg 1",
+		"debug root.hot msg 4",
+		"debug root.hot msg 7",
+	}, hotDebug)
+	assert.Equal(t, 10, strings.Count(buf.String(), "info root.hot"))
+	assert.Equal(t, 10, strings.Count(buf.String(), "debug root "))
+
+	// counters are reset after the interval
+	buf.Reset()
+	now = now.Add(time.Second)
+	hot.Debugf("msg %d", 10)
+	hot.Debug("different message")
+	assert.Equal(t, "debug root.hot msg 10\ndebug root.hot different message\n", buf.String())
+}
+
+func TestSampler_Summary(t *testing.T) {
+	sampler := NewSampler(SamplingOptions{
+		Interval: time.Hour,
+		First:    1,
+		Summary:  true,
+	})
+	logger, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithLevel(TraceLevel).WithSampler("root.hot", sampler)
+	})
+	hot := logger.New("hot")
+
+	for i := 0; i < 5; i++ {
+		hot.Warnf("msg %d", i)
+	}
+	buf.Reset()
+
+	sampler.logSummaries()
+	assert.Equal(t, "warn root.hot 4 messages suppressed sampled_message=msg %d\n", buf.String())
+
+	buf.Reset()
+	sampler.logSummaries()
+	assert.Empty(t, buf.String())
+
+	hot.Warnf("msg %d", 5)
+	assert.NoError(t, sampler.Close())
+	assert.Equal(t, "warn root.hot 1 messages suppressed sampled_message=msg %d\n", buf.String())
+}
+
+func TestSampler_PerLevel(t *testing.T) {
+	sampler := NewSampler(SamplingOptions{
+		Interval: time.Hour,
+		First:    1,
+		Levels:   []Level{DebugLevel},
+		PerLevel: map[Level]SamplingRate{
+			InfoLevel: {First: 2, Thereafter: 2},
+		},
+	})
+	logger, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithLevel(TraceLevel).WithSampler("", sampler)
+	})
+
+	for i := 0; i < 6; i++ {
+		logger.Debugf("debug %d", i)
+		logger.Infof("info %d", i)
+		logger.Warnf("warn %d", i)
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "debug root"))
+	assert.Equal(t, 4, strings.Count(buf.String(), "info root"))
+	assert.Contains(t, buf.String(), "info root info 3\n")
+	assert.Contains(t, buf.String(), "info root info 5\n")
+	assert.Equal(t, 6, strings.Count(buf.String(), "warn root"))
+}
+
+func TestSampler_SummaryPerMessage(t *testing.T) {
+	sampler := NewSampler(SamplingOptions{
+		Interval: time.Hour,
+		First:    1,
+		Summary:  true,
+	})
+	logger, buf, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithLevel(TraceLevel).WithSampler("", sampler)
+	})
+
+	for i := 0; i < 300; i++ {
+		logger.Info(fmt.Sprintf("msg %02d", i%100)) // 100 different messages
+		logger.Infof("template %d", i)
+	}
+	buf.Reset()
+	assert.NoError(t, sampler.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 101)
+	assert.Equal(t, "info root 2 messages suppressed sampled_message=msg 00", lines[0])
+	assert.Equal(t, "info root 2 messages suppressed sampled_message=msg 99", lines[99])
+	assert.Equal(t, "info root 299 messages suppressed sampled_message=template %d", lines[100])
+	assert.Equal(t, uint64(499), sampler.Dropped())
+}
+
+func TestSampler_NoSummary(t *testing.T) {
+	now := time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC)
+	sampler := NewSampler(SamplingOptions{
+		First: 1,
+	})
+	sampler.now = func() time.Time { return now }
+	logger, _, _ := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithSampler("", sampler)
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.Info("msg")
+		logger.Infof("other %d", i)
+	}
+	assert.Len(t, sampler.counters, 2)
+	for _, counter := range sampler.counters {
+		assert.Zero(t, counter.suppressed)
+		assert.Nil(t, counter.logger)
+	}
+
+	// counters of expired intervals are removed
+	now = now.Add(time.Second)
+	logger.Info("msg")
+	assert.Len(t, sampler.counters, 1)
+}
@@ -0,0 +1,39 @@
+package mlog
+
+import (
+	"io"
+	"sync"
+)
+
+// sink is an additional output with its own level and formatter.
+type sink struct {
+	m         sync.Mutex
+	out       io.Writer
+	level     Level
+	formatter EntryFormatter
+}
+
+// levels returns all levels written into the sink.
+func (s *sink) levels() []Level {
+	var levels []Level
+	for _, lvl := range Levels() {
+		if !lvl.LessSevereThan(s.level) {
+			levels = append(levels, lvl)
+		}
+	}
+	return levels
+}
+
+func (s *sink) write(entry *Entry) error {
+	e := *entry
+	e.output = s.out
+	data, err := s.formatter(&e)
+	if err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	_, err = s.out.Write(data)
+	return err
+}
@@ -0,0 +1,85 @@
+package mlog
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerBuilder_AddSink(t *testing.T) {
+	console := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	errorFile := &bytes.Buffer{}
+
+	logger := NewLoggerBuilder("root").
+		WithOutput(ioutil.Discard).
+		WithLevel(DebugLevel).
+		AddSink(console, InfoLevel, nil).
+		AddSink(file, DebugLevel, LogfmtFormatter).
+		AddSink(errorFile, ErrorLevel, func(entry *Entry) ([]byte, error) {
+			return []byte(entry.Message + "\n"), nil
+		}).
+		Create()
+
+	logger.Trace("trace")
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.New("sub").Error("error")
+
+	assert.Contains(t, console.String(), "INFO                                     root ▶ info\n")
+	assert.Contains(t, console.String(), "ERRO                                 root.sub ▶ error\n")
+	assert.NotContains(t, console.String(), "debug")
+	assert.NotContains(t, console.String(), "\x1b[")
+
+	assert.Contains(t, file.String(), "level=debug name=root msg=debug\n")
+	assert.Contains(t, file.String(), "level=info name=root msg=info\n")
+	assert.Contains(t, file.String(), "level=error name=root.sub msg=error\n")
+	assert.NotContains(t, file.String(), "trace")
+
+	assert.Equal(t, "error\n", errorFile.String())
+}
+
+func TestLoggerBuilder_AddSinkLevel(t *testing.T) {
+	sink := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(ioutil.Discard).
+		WithLevel(InfoLevel).
+		AddSink(sink, TraceLevel, func(entry *Entry) ([]byte, error) {
+			return []byte(entry.Message + "\n"), nil
+		}).
+		Create()
+
+	logger.Debug("debug")
+	logger.Info("info")
+	assert.Equal(t, "info\n", sink.String())
+}
+
+func TestLoggerBuilder_AddSinkError(t *testing.T) {
+	var handled []*HookError
+	sinkErr := errors.New("write failed")
+	logger := NewLoggerBuilder("root").
+		WithOutput(ioutil.Discard).
+		WithHookErrorHandler(func(err *HookError) {
+			handled = append(handled, err)
+		}).
+		AddSink(ioutil.Discard, InfoLevel, func(entry *Entry) ([]byte, error) {
+			return nil, sinkErr
+		}).
+		Create().(*StdLogger)
+
+	logger.Debug("debug")
+	logger.Warn("warn")
+
+	assert.Len(t, handled, 1)
+	assert.Equal(t, sinkErr, handled[0].Err)
+	assert.Equal(t, "warn", handled[0].Entry.Message)
+
+	stats := logger.HookStats()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, uint64(1), stats[0].Calls)
+	assert.Equal(t, uint64(1), stats[0].Failures)
+	assert.Equal(t, sinkErr, stats[0].LastError)
+}
@@ -0,0 +1,475 @@
+package mlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// SlogLevel converts a log level into the corresponding slog level.
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case PanicLevel:
+		return slog.LevelError + 8
+	case FatalLevel:
+		return slog.LevelError + 4
+	case ErrorLevel:
+		return slog.LevelError
+	case WarnLevel:
+		return slog.LevelWarn
+	case InfoLevel:
+		return slog.LevelInfo
+	case DebugLevel:
+		return slog.LevelDebug
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	default: // custom
+		return SlogLevel(level.closestBuiltin())
+	}
+}
+
+// LevelFromSlog converts a slog level into the closest log level.
+// Levels between the predefined slog levels are rounded down.
+func LevelFromSlog(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError+8:
+		return PanicLevel
+	case level >= slog.LevelError+4:
+		return FatalLevel
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	case level >= slog.LevelDebug:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+// SlogHandler is a slog.Handler that passes records to a Logger.
+// Groups are converted into sub-loggers with dotted names, attributes are converted into fields.
+// Group attributes within records are flattened into dotted field keys.
+type SlogHandler struct {
+	logger Logger
+}
+
+// NewSlogHandler returns a slog handler that passes all records to the given logger.
+func NewSlogHandler(logger Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(LevelFromSlog(level))
+}
+
+// Handle logs the record. Fields of registered context values are added. See RegisterContextKey.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	logger := h.logger
+	if record.NumAttrs() > 0 {
+		fields := make(Fields, record.NumAttrs())
+		record.Attrs(func(attr slog.Attr) bool {
+			addSlogAttr(fields, "", attr)
+			return true
+		})
+		logger = logger.WithFields(fields)
+	}
+	if ctx == nil {
+		logger.Log(LevelFromSlog(record.Level), record.Message)
+		return nil
+	}
+	logger.LogCtx(ctx, LevelFromSlog(record.Level), record.Message)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make(Fields, len(attrs))
+	for _, attr := range attrs {
+		addSlogAttr(fields, "", attr)
+	}
+	return &SlogHandler{
+		logger: h.logger.WithFields(fields),
+	}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		logger: h.logger.New(name),
+	}
+}
+
+// addSlogAttr adds the attribute to the fields.
+// Groups are flattened using dotted keys.
+func addSlogAttr(fields Fields, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		fields[prefix+attr.Key] = attr.Value.Any()
+		return
+	}
+	if attr.Key != "" {
+		prefix += attr.Key + nameSeparator
+	}
+	for _, a := range attr.Value.Group() {
+		addSlogAttr(fields, prefix, a)
+	}
+}
+
+// slogNameKey is the attribute key containing the logger name.
+const slogNameKey = "name"
+
+// slogLogger passes log messages to a slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+	name   string
+	level  *uint32 // accessed atomically
+	ctx    context.Context
+	v      int // verbosity; lowers the slog level, see V
+}
+
+// FromSlog returns a logger that passes all messages to the given slog logger.
+// The logger name is added as "name" attribute, fields are added as attributes.
+// The level of the returned logger defaults to TraceLevel, so that the slog handler decides which messages are logged.
+func FromSlog(logger *slog.Logger) Logger {
+	level := uint32(TraceLevel)
+	return &slogLogger{
+		logger: logger,
+		level:  &level,
+		ctx:    context.Background(),
+	}
+}
+
+func (l *slogLogger) New(name ...string) Logger {
+	level := atomic.LoadUint32(l.level)
+	return &slogLogger{
+		logger: l.logger,
+		name:   appendLoggerNameComponents(l.name, name...),
+		level:  &level,
+		ctx:    l.ctx,
+		v:      l.v,
+	}
+}
+
+func (l *slogLogger) With(keyvals ...interface{}) Logger {
+	return l.WithFields(keyValsToFields(keyvals...))
+}
+
+func (l *slogLogger) WithFields(fields Fields) Logger {
+	return &slogLogger{
+		logger: l.logger.With(fieldsToSlogArgs(fields)...),
+		name:   l.name,
+		level:  l.level,
+		ctx:    l.ctx,
+		v:      l.v,
+	}
+}
+
+// WithContext returns a new logger with the fields of all registered context values added.
+// The context is passed to the slog handler.
+func (l *slogLogger) WithContext(ctx context.Context) Logger {
+	return &slogLogger{
+		logger: l.logger.With(fieldsToSlogArgs(ContextFields(ctx))...),
+		name:   l.name,
+		level:  l.level,
+		ctx:    ctx,
+		v:      l.v,
+	}
+}
+
+func (l *slogLogger) WithError(err error) Logger {
+	return l.WithFields(Fields{errorKey: err})
+}
+
+// V returns a logger whose messages have a slog level lowered by v, following the conventions of go-logr.
+// For example, V(2).Info logs with slog level INFO-2. Sub-loggers keep the verbosity.
+func (l *slogLogger) V(v int) Logger {
+	clone := *l
+	clone.v = v
+	return &clone
+}
+
+// slogLevel returns the slog level of messages with the given level, considering the verbosity.
+func (l *slogLogger) slogLevel(level Level) slog.Level {
+	return SlogLevel(level) - slog.Level(l.v)
+}
+
+func fieldsToSlogArgs(fields Fields) []interface{} {
+	args := make([]interface{}, 0, len(fields))
+	for k, v := range fields {
+		args = append(args, slog.Any(k, v))
+	}
+	return args
+}
+
+func (l *slogLogger) Name() string {
+	return l.name
+}
+
+func (l *slogLogger) Level() Level {
+	return Level(atomic.LoadUint32(l.level))
+}
+
+func (l *slogLogger) SetLevel(level Level) {
+	atomic.StoreUint32(l.level, uint32(level))
+}
+
+func (l *slogLogger) IsLevelEnabled(level Level) bool {
+	if level.LessSevereThan(l.Level()) {
+		return false
+	}
+	return l.logger.Enabled(l.ctx, l.slogLevel(level))
+}
+
+func (l *slogLogger) WriterLevel(level Level) io.WriteCloser {
+	return newLineWriter(func(line string) {
+		l.log(l.ctx, level, line)
+	})
+}
+
+// log passes the message to the slog logger.
+// Must be called directly by the exported log methods to determine the correct caller.
+// If the context differs from the logger's context, the fields of registered context values are added.
+func (l *slogLogger) log(ctx context.Context, level Level, msg string) {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip runtime.Callers, log and the exported log method
+
+	record := slog.NewRecord(time.Now(), l.slogLevel(level), msg, pcs[0])
+	if l.name != "" {
+		record.AddAttrs(slog.String(slogNameKey, l.name))
+	}
+	if ctx != l.ctx {
+		record.Add(fieldsToSlogArgs(ContextFields(ctx))...)
+	}
+	_ = l.logger.Handler().Handle(ctx, record)
+}
+
+func (l *slogLogger) Log(level Level, args ...interface{}) {
+	if l.IsLevelEnabled(level) {
+		l.log(l.ctx, level, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) Logf(level Level, format string, args ...interface{}) {
+	if l.IsLevelEnabled(level) {
+		l.log(l.ctx, level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) Trace(args ...interface{}) {
+	if l.IsLevelEnabled(TraceLevel) {
+		l.log(l.ctx, TraceLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) Tracef(format string, args ...interface{}) {
+	if l.IsLevelEnabled(TraceLevel) {
+		l.log(l.ctx, TraceLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) Debug(args ...interface{}) {
+	if l.IsLevelEnabled(DebugLevel) {
+		l.log(l.ctx, DebugLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	if l.IsLevelEnabled(DebugLevel) {
+		l.log(l.ctx, DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) Info(args ...interface{}) {
+	if l.IsLevelEnabled(InfoLevel) {
+		l.log(l.ctx, InfoLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	if l.IsLevelEnabled(InfoLevel) {
+		l.log(l.ctx, InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) Warn(args ...interface{}) {
+	if l.IsLevelEnabled(WarnLevel) {
+		l.log(l.ctx, WarnLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	if l.IsLevelEnabled(WarnLevel) {
+		l.log(l.ctx, WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) Error(args ...interface{}) {
+	if l.IsLevelEnabled(ErrorLevel) {
+		l.log(l.ctx, ErrorLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	if l.IsLevelEnabled(ErrorLevel) {
+		l.log(l.ctx, ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+// Fatal logs the message and terminates the program. See SetExitFunc.
+func (l *slogLogger) Fatal(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	if l.IsLevelEnabled(FatalLevel) {
+		l.log(l.ctx, FatalLevel, msg)
+	}
+	exit(1)
+}
+
+// Fatalf logs the message and terminates the program. See SetExitFunc.
+func (l *slogLogger) Fatalf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if l.IsLevelEnabled(FatalLevel) {
+		l.log(l.ctx, FatalLevel, msg)
+	}
+	exit(1)
+}
+
+func (l *slogLogger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	if l.IsLevelEnabled(PanicLevel) {
+		l.log(l.ctx, PanicLevel, msg)
+	}
+	panic(msg)
+}
+
+func (l *slogLogger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if l.IsLevelEnabled(PanicLevel) {
+		l.log(l.ctx, PanicLevel, msg)
+	}
+	panic(msg)
+}
+
+func (l *slogLogger) LogFn(level Level, fn func() string) {
+	if l.IsLevelEnabled(level) {
+		l.log(l.ctx, level, fn())
+	}
+}
+
+func (l *slogLogger) TraceFn(fn func() string) {
+	if l.IsLevelEnabled(TraceLevel) {
+		l.log(l.ctx, TraceLevel, fn())
+	}
+}
+
+func (l *slogLogger) DebugFn(fn func() string) {
+	if l.IsLevelEnabled(DebugLevel) {
+		l.log(l.ctx, DebugLevel, fn())
+	}
+}
+
+func (l *slogLogger) InfoFn(fn func() string) {
+	if l.IsLevelEnabled(InfoLevel) {
+		l.log(l.ctx, InfoLevel, fn())
+	}
+}
+
+func (l *slogLogger) WarnFn(fn func() string) {
+	if l.IsLevelEnabled(WarnLevel) {
+		l.log(l.ctx, WarnLevel, fn())
+	}
+}
+
+func (l *slogLogger) ErrorFn(fn func() string) {
+	if l.IsLevelEnabled(ErrorLevel) {
+		l.log(l.ctx, ErrorLevel, fn())
+	}
+}
+
+func (l *slogLogger) LogCtx(ctx context.Context, level Level, args ...interface{}) {
+	if l.IsLevelEnabled(level) {
+		l.log(ctx, level, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) LogfCtx(ctx context.Context, level Level, format string, args ...interface{}) {
+	if l.IsLevelEnabled(level) {
+		l.log(ctx, level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) TraceCtx(ctx context.Context, args ...interface{}) {
+	if l.IsLevelEnabled(TraceLevel) {
+		l.log(ctx, TraceLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.IsLevelEnabled(TraceLevel) {
+		l.log(ctx, TraceLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) DebugCtx(ctx context.Context, args ...interface{}) {
+	if l.IsLevelEnabled(DebugLevel) {
+		l.log(ctx, DebugLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.IsLevelEnabled(DebugLevel) {
+		l.log(ctx, DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) InfoCtx(ctx context.Context, args ...interface{}) {
+	if l.IsLevelEnabled(InfoLevel) {
+		l.log(ctx, InfoLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.IsLevelEnabled(InfoLevel) {
+		l.log(ctx, InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) WarnCtx(ctx context.Context, args ...interface{}) {
+	if l.IsLevelEnabled(WarnLevel) {
+		l.log(ctx, WarnLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.IsLevelEnabled(WarnLevel) {
+		l.log(ctx, WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *slogLogger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	if l.IsLevelEnabled(ErrorLevel) {
+		l.log(ctx, ErrorLevel, fmt.Sprint(args...))
+	}
+}
+
+func (l *slogLogger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if l.IsLevelEnabled(ErrorLevel) {
+		l.log(ctx, ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
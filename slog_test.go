@@ -0,0 +1,87 @@
+package mlog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	mem := NewMemLogger()
+	logger := slog.New(NewSlogHandler(mem))
+
+	logger.Info("info", "a", 1)
+	logger.With("b", true).WithGroup("sub").Warn("warn", slog.Group("g", "c", "x"))
+	logger.Debug("debug")
+
+	mem.AssertAllMessages(t, InfoLevel, "info")
+	mem.AssertAllMessages(t, WarnLevel, "warn")
+	mem.AssertAllMessages(t, DebugLevel, "debug")
+	assert.Equal(t, []Fields{{"a": int64(1)}}, mem.Fields(InfoLevel))
+	assert.Equal(t, []Fields{{"b": true, "g.c": "x"}}, mem.Fields(WarnLevel))
+}
+
+func TestSlogHandler_Context(t *testing.T) {
+	key := testContextKey("slog")
+	t.Cleanup(RegisterContextKey(key, "slog_id"))
+
+	mem := NewMemLogger()
+	logger := slog.New(NewSlogHandler(mem))
+	ctx := context.WithValue(context.Background(), key, 7)
+	logger.InfoContext(ctx, "msg", "a", 1)
+
+	assert.Equal(t, []Fields{{"a": int64(1), "slog_id": 7}}, mem.Fields(InfoLevel))
+	assert.Equal(t, ctx, mem.Entries(InfoLevel)[0].Context)
+}
+
+func TestSlogHandler_GroupNames(t *testing.T) {
+	root, buf := newTestLogger("root")
+	root.SetLevel(DebugLevel)
+	logger := slog.New(NewSlogHandler(root))
+
+	logger.WithGroup("db").WithGroup("pool").Info("msg", "k", "v")
+	logger.Debug("debug")
+	logger.Log(context.Background(), slog.LevelDebug-1, "trace")
+
+	assert.Equal(t, "info root.db.pool msg k=v\ndebug root debug\n", buf.String())
+}
+
+func TestFromSlog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := FromSlog(slog.New(handler))
+
+	logger.Info("info")
+	logger.New("db", "pool").With("k", "v").Warnf("warn %d", 1)
+	logger.Trace("trace")
+	assert.True(t, logger.IsLevelEnabled(DebugLevel))
+	assert.False(t, logger.IsLevelEnabled(TraceLevel))
+
+	logger.SetLevel(ErrorLevel)
+	logger.Warn("disabled")
+	assert.Equal(t, ErrorLevel, logger.Level())
+
+	assert.Equal(t, "level=INFO msg=info\nlevel=WARN msg=\"warn 1\" k=v name=db.pool\n", buf.String())
+}
+
+func TestSlogLevel(t *testing.T) {
+	for _, lvl := range AllLevels {
+		if lvl.isBuiltin() {
+			assert.Equal(t, lvl, LevelFromSlog(SlogLevel(lvl)))
+		} else {
+			assert.Equal(t, lvl.closestBuiltin(), LevelFromSlog(SlogLevel(lvl)))
+		}
+	}
+	assert.Equal(t, InfoLevel, LevelFromSlog(slog.LevelInfo+1))
+}
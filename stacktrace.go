@@ -0,0 +1,110 @@
+package mlog
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// stackTraceKey is the key of the logrus entry field containing the stack trace.
+// It is not exposed as a user-defined field.
+const stackTraceKey = "mlog.stacktrace"
+
+// maxStackDepth is the maximum number of captured stack frames.
+const maxStackDepth = 64
+
+// captureStackTrace returns the stack of the current goroutine, starting at the call site of the log statement.
+// See callerFrame.
+func captureStackTrace(skip int) []runtime.Frame {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip runtime.Callers and captureStackTrace
+	frames := runtime.CallersFrames(pcs[:n])
+
+	first, ok := skipWrapperFrames(frames, skip)
+	if !ok {
+		return nil
+	}
+	stack := []runtime.Frame{first}
+	for {
+		frame, more := frames.Next()
+		if !more {
+			return stack
+		}
+		stack = append(stack, frame)
+	}
+}
+
+// errorStackTrace returns the stack trace at which the error was created.
+// Supports errors with a StackTrace method returning program counters, like errors of github.com/pkg/errors.
+// If multiple errors within the chain of wrapped errors provide a stack trace, the innermost one is used.
+// Returns nil if there is no stack trace.
+func errorStackTrace(err error) []runtime.Frame {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if errPCs, ok := stackTracePCs(err); ok {
+			pcs = errPCs
+		}
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			return stack
+		}
+	}
+}
+
+// stackTracePCs calls the error's StackTrace method, if it exists.
+// The method must return a slice of program counters, whose types are usually package-specific.
+func stackTracePCs(err error) ([]uintptr, bool) {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() {
+		return nil, false
+	}
+	typ := method.Type()
+	if typ.NumIn() != 0 || typ.NumOut() != 1 ||
+		typ.Out(0).Kind() != reflect.Slice || typ.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+
+	trace := method.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs, true
+}
+
+// findStackTrace returns the stack trace of the first error within the arguments that provides one.
+func findStackTrace(args []interface{}) []runtime.Frame {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if stack := errorStackTrace(err); stack != nil {
+				return stack
+			}
+		}
+	}
+	return nil
+}
+
+// formatStackTrace returns a multi-line representation of the stack trace.
+// Each frame consists of the function name, followed by the indented file and line.
+func formatStackTrace(stack []runtime.Frame, indent string) string {
+	var sb strings.Builder
+	for i, frame := range stack {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(indent + frame.Function + "\n")
+		sb.WriteString(indent + "\t" + frame.File + ":" + strconv.Itoa(frame.Line))
+	}
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package mlog
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogger_StackTrace(t *testing.T) {
+	logger, _, entries := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithStackTrace()
+	})
+
+	line := currentLine()
+	logger.Error("msg")
+	logger.Warn("msg")
+
+	assert.Len(t, *entries, 2)
+	stack := (*entries)[0].StackTrace
+	assert.NotEmpty(t, stack)
+	assert.Equal(t, "stacktrace_test.go", path.Base(stack[0].File))
+	assert.Equal(t, line+1, stack[0].Line)
+	assert.Equal(t, "testing.tRunner", stack[1].Function)
+	assert.NotContains(t, (*entries)[0].Fields, stackTraceKey)
+
+	assert.Nil(t, (*entries)[1].StackTrace)
+}
+
+func newPkgError() (error, int) {
+	return pkgerrors.New("failure"), currentLine()
+}
+
+func TestStdLogger_ErrorStackTrace(t *testing.T) {
+	logger, _, entries := newEntryTestLogger(func(b *LoggerBuilder) {
+		b.WithStackTrace(WarnLevel, ErrorLevel)
+	})
+
+	err, line := newPkgError()
+	wrapped := fmt.Errorf("context: %w", pkgerrors.Wrap(err, "wrapped"))
+
+	logger.Warn("msg", err)
+	logger.Errorf("msg: %v", wrapped)
+	logger.With("error", wrapped).Error("msg")
+
+	assert.Len(t, *entries, 3)
+	for _, entry := range *entries {
+		stack := entry.StackTrace
+		assert.NotEmpty(t, stack)
+		assert.Equal(t, "github.com/maja42/mlog.newPkgError", stack[0].Function)
+		assert.Equal(t, line, stack[0].Line)
+	}
+}
+
+func Test_formatStackTrace(t *testing.T) {
+	stack := []runtime.Frame{
+		{Function: "pkg.a", File: "/src/pkg/a.go", Line: 1},
+		{Function: "pkg.b", File: "/src/pkg/b.go", Line: 2},
+	}
+	assert.Equal(t, "\tpkg.a\n\t\t/src/pkg/a.go:1\n\tpkg.b\n\t\t/src/pkg/b.go:2", formatStackTrace(stack, "\t"))
+
+	formatter := NewConsoleFormatter(ConsoleOptions{
+		NameWidth: -1,
+		Color:     ColorNever,
+	})
+	data, err := formatter(&Entry{
+		Time:       time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:      ErrorLevel,
+		Name:       "root",
+		Message:    "msg",
+		StackTrace: stack[:1],
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "15.05.2021 12:30:00 ERRO root ▶ msg\n\tpkg.a\n\t\t/src/pkg/a.go:1\n", string(data))
+
+	data, err = JSONFormatter(&Entry{
+		Time:       time.Date(2021, 5, 15, 12, 30, 0, 0, time.UTC),
+		Level:      ErrorLevel,
+		Message:    "msg",
+		StackTrace: stack[:1],
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"time":"2021-05-15T12:30:00Z","level":"error","name":"","msg":"msg",`+
+		`"stacktrace":"pkg.a\n\t/src/pkg/a.go:1"}`+"\n", string(data))
+}
+
+func TestConsoleFormatter_StackTraceViaBuilder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithEntryFormatter(NewConsoleFormatter(ConsoleOptions{})).
+		WithStackTrace().
+		Create()
+
+	line := currentLine()
+	logger.Error("msg")
+	assert.Contains(t, buf.String(), "\tgithub.com/maja42/mlog.TestConsoleFormatter_StackTraceViaBuilder\n")
+	assert.Contains(t, buf.String(), fmt.Sprintf("stacktrace_test.go:%d", line+1))
+}
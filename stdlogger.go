@@ -1,7 +1,11 @@
 package mlog
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"math"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -9,128 +13,562 @@ import (
 type StdLogger struct {
 	name     string
 	rawEntry *logrus.Entry
+	level    *levelState // shared by all loggers of the scope with the same name
+	scope    *levelScope // nil if the logger is not tracked
+	v        int         // required verbosity; see V
+	tree     *loggerTree
+}
+
+// levelUnset marks loggers without an own level, which inherit the level of their parent.
+const levelUnset = math.MaxUint32
+
+// levelState is the level shared by all loggers of a level scope with the same name.
+type levelState struct {
+	level  uint32      // accessed atomically
+	parent *levelState // to inherit the level from; nil if level inheritance is disabled
+}
+
+// get returns the level set via set, or the inherited level.
+func (s *levelState) get() Level {
+	lvl := atomic.LoadUint32(&s.level)
+	if lvl == levelUnset && s.parent != nil {
+		return s.parent.get()
+	}
+	return Level(lvl)
+}
+
+func (s *levelState) set(level Level) {
+	atomic.StoreUint32(&s.level, uint32(level))
 }
 
 // NewStdLogger returns a new standard logger.
 func NewStdLogger(name ...string) *StdLogger {
+	logger := newStdLogger(name...)
+	logger.scope = logger.tree.newLevelScope()
+	logger.tree.levelState(logger.scope, logger.name, func() *levelState {
+		return logger.level
+	})
+	return logger
+}
+
+// newStdLogger returns a new standard logger without tracking it.
+func newStdLogger(name ...string) *StdLogger {
 	loggerName := appendLoggerNameComponents("", name...)
-	logger := &StdLogger{
+	return &StdLogger{
 		name:     loggerName,
-		rawEntry: newLogrusEntry(logrus.New(), loggerName),
+		rawEntry: newLogrusEntry(newLogrusLogger(), loggerName, nil),
+		level:    &levelState{level: uint32(InfoLevel)},
+		tree:     newLoggerTree(),
 	}
+}
+
+// newLogrusLogger returns a new logrus logger.
+// Level checks are performed by the StdLogger, the logrus logger processes all entries, including custom levels.
+func newLogrusLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.Level = logrus.Level(math.MaxUint32)
 	return logger
 }
 
-func newLogrusEntry(logger *logrus.Logger, loggerName string) *logrus.Entry {
+// nameKey is the key of the logrus entry field containing the logger name.
+const nameKey = "name"
+
+func newLogrusEntry(logger *logrus.Logger, loggerName string, fields Fields) *logrus.Entry {
+	data := make(logrus.Fields, len(fields)+1)
+	for k, v := range fields {
+		if k == nameKey {
+			k = "fields." + k // prevent clashes with reserved keys
+		}
+		data[k] = v
+	}
+	data[nameKey] = loggerName
 	return &logrus.Entry{
 		Logger: logger,
-		Data: map[string]interface{}{
-			"name": loggerName,
-		},
+		Data:   data,
 	}
 }
 
+// fields returns the user-defined fields of the logger.
+func (l *StdLogger) fields() Fields {
+	return logrusDataToFields(l.rawEntry.Data)
+}
+
+// logrusDataToFields returns all user-defined fields of a logrus entry.
+func logrusDataToFields(data logrus.Fields) Fields {
+	fields := make(Fields, len(data))
+	for k, v := range data {
+		if k == nameKey || k == callerKey || k == stackTraceKey || k == argErrorKey {
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
+}
+
 func (l *StdLogger) rawLogger() *logrus.Logger {
 	return l.rawEntry.Logger
 }
 
 // New returns a new sub-logger.
+// Sub-loggers with the same name share their level, unless they were derived from different clones.
+// The level of a new name is copied from this logger, or inherited if level inheritance is enabled.
 func (l *StdLogger) New(name ...string) Logger {
 	loggerName := appendLoggerNameComponents(l.name, name...)
 
 	rawLogger := copyLogrusLogger(l.rawLogger())
 
-	newLogger := &StdLogger{
+	level := l.tree.levelState(l.scope, loggerName, func() *levelState {
+		if loggerName == l.name {
+			return l.level
+		}
+		if l.tree.inheritLevel {
+			return &levelState{level: levelUnset, parent: l.level}
+		}
+		return &levelState{level: uint32(l.ownLevel())}
+	})
+
+	return &StdLogger{
 		name:     loggerName,
-		rawEntry: newLogrusEntry(rawLogger, loggerName),
+		rawEntry: newLogrusEntry(rawLogger, loggerName, l.fields()),
+		level:    level,
+		scope:    l.scope,
+		v:        l.v,
+		tree:     l.tree,
+	}
+}
+
+// With returns a new logger with additional fields.
+func (l *StdLogger) With(keyvals ...interface{}) Logger {
+	return l.WithFields(keyValsToFields(keyvals...))
+}
+
+// WithFields returns a new logger with additional fields.
+// The new logger shares its level with this logger and all other loggers with the same name, see New.
+func (l *StdLogger) WithFields(fields Fields) Logger {
+	return l.withFields(fields, l.rawEntry.Context)
+}
+
+// WithContext returns a new logger with the fields of all registered context values added.
+// The context is passed to hooks and formatters via Entry.Context.
+func (l *StdLogger) WithContext(ctx context.Context) Logger {
+	return l.withFields(ContextFields(ctx), ctx)
+}
+
+func (l *StdLogger) withFields(fields Fields, ctx context.Context) *StdLogger {
+	rawEntry := newLogrusEntry(l.rawLogger(), l.name, mergeFields(l.fields(), fields))
+	rawEntry.Context = ctx
+	return &StdLogger{
+		name:     l.name,
+		rawEntry: rawEntry,
+		level:    l.level,
+		scope:    l.scope,
+		v:        l.v,
+		tree:     l.tree,
 	}
-	return newLogger
+}
+
+// V returns a logger that only logs messages if the verbosity configured for the logger's name is at least v.
+// The returned logger shares its fields and level with this logger. Sub-loggers keep the verbosity.
+func (l *StdLogger) V(v int) Logger {
+	clone := *l
+	clone.v = v
+	return &clone
+}
+
+// VerbosityRegistry returns the verbosity registry shared by the logger and all its sub-loggers.
+func (l *StdLogger) VerbosityRegistry() *VerbosityRegistry {
+	return l.tree.verbosity
+}
+
+// WithError returns a new logger with the error attached to all log messages.
+// The error is available to formatters and hooks via Entry.Error.
+func (l *StdLogger) WithError(err error) Logger {
+	return l.WithFields(Fields{errorKey: err})
 }
 
 func copyLogrusLogger(old *logrus.Logger) *logrus.Logger {
-	clone := logrus.New()
+	clone := newLogrusLogger()
 	clone.Out = old.Out
 	clone.Hooks = old.Hooks
 	clone.Formatter = old.Formatter
-	clone.Level = old.Level
 	return clone
 }
 
-// Clone returns a copy of this logger
+// Clone returns a copy of this logger.
+// The copy has its own level, which is independent of this logger and its sub-loggers.
 func (l *StdLogger) Clone() Logger {
-	return l.New()
+	scope := l.tree.newLevelScope()
+	level := l.tree.levelState(scope, l.name, func() *levelState {
+		return &levelState{
+			level:  atomic.LoadUint32(&l.level.level),
+			parent: l.level.parent,
+		}
+	})
+	return &StdLogger{
+		name:     l.name,
+		rawEntry: newLogrusEntry(copyLogrusLogger(l.rawLogger()), l.name, l.fields()),
+		level:    level,
+		scope:    scope,
+		v:        l.v,
+		tree:     l.tree,
+	}
 }
 
 func (l *StdLogger) Name() string {
 	return l.name
 }
 
+// Level returns the logger's effective log level.
+// Levels configured in the level registry take precedence over the logger's own level.
 func (l *StdLogger) Level() Level {
-	return Level(l.rawLogger().Level)
+	return l.tree.level(l.name, l.level)
+}
+
+// ownLevel returns the level set via SetLevel, or the inherited level.
+func (l *StdLogger) ownLevel() Level {
+	return l.level.get()
 }
 
 func (l *StdLogger) IsLevelEnabled(level Level) bool {
-	return l.rawLogger().IsLevelEnabled(logrus.Level(level))
+	if l.v > 0 && l.tree.verbosity.Verbosity(l.name) < l.v {
+		return false
+	}
+	return !level.LessSevereThan(l.Level())
 }
 
+// SetLevel changes the log level of the logger and all other loggers with the same name,
+// except for loggers derived from a different clone.
+// Does not modify the level of parent-loggers.
+// If level inheritance is enabled, sub-loggers without an own level follow the new level.
+// Has no effect while the level registry contains a level for the logger's name.
 func (l *StdLogger) SetLevel(level Level) {
-	l.rawLogger().Level = logrus.Level(level)
+	l.level.set(level)
+}
+
+// ResetLevel removes the level set via SetLevel, so that the logger follows the level of its parent again.
+// Has no effect if level inheritance is disabled or the logger has no parent.
+func (l *StdLogger) ResetLevel() {
+	if l.level.parent == nil {
+		return
+	}
+	atomic.StoreUint32(&l.level.level, levelUnset)
+}
+
+// LevelRegistry returns the level registry shared by the logger and all its sub-loggers.
+func (l *StdLogger) LevelRegistry() *LevelRegistry {
+	return l.tree.levels
 }
 
 func (l *StdLogger) WriterLevel(level Level) io.WriteCloser {
-	return l.rawEntry.WriterLevel(logrus.Level(level))
+	return newLineWriter(func(line string) {
+		l.Log(level, line)
+	})
 }
 
 func (l *StdLogger) Log(level Level, args ...interface{}) {
-	l.rawEntry.Log(logrus.Level(level), args...)
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	l.log(level, &logMessage{args: args})
 }
 
 func (l *StdLogger) Logf(level Level, format string, args ...interface{}) {
-	l.rawEntry.Logf(logrus.Level(level), format, args...)
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	l.log(level, &logMessage{format: format, printf: true, args: args})
+}
+
+// log writes the message, unless it is dropped by deduplication, sampling or rate limiting.
+func (l *StdLogger) log(level Level, m *logMessage) {
+	if !l.tree.allow(l, level, m) {
+		return
+	}
+	msg := m.text() // resolves lazy arguments
+	l.write(level, m.args, msg)
+}
+
+// logMessage is a message that is formatted when it is first needed.
+// Lazy arguments are resolved once, on the calling goroutine.
+type logMessage struct {
+	format string // of Logf
+	printf bool
+	args   []interface{}
+
+	msg       string
+	formatted bool
+}
+
+// text returns the formatted message.
+func (m *logMessage) text() string {
+	if !m.formatted {
+		m.args = resolveLazy(m.args)
+		if m.printf {
+			m.msg = fmt.Sprintf(m.format, m.args...)
+		} else {
+			m.msg = fmt.Sprint(m.args...)
+		}
+		m.formatted = true
+	}
+	return m.msg
+}
+
+// template identifies similar messages, like the format string of Logf.
+func (m *logMessage) template() string {
+	if m.printf {
+		return m.format
+	}
+	return m.text()
+}
+
+// write passes the message to logrus.
+// Logrus panics after logging messages with PanicLevel, which is suppressed.
+func (l *StdLogger) write(level Level, args []interface{}, msg string) {
+	if level == PanicLevel {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(*logrus.Entry); !ok {
+					panic(r)
+				}
+			}
+		}()
+	}
+	l.entry(level, args).Log(logrus.Level(level), msg)
+}
+
+// entry returns the logrus entry for a new log message.
+// Contains the first error argument, unless the logger already has an error attached,
+// as well as the call site and stack trace if enabled. Lazy field values are resolved.
+func (l *StdLogger) entry(level Level, args []interface{}) *logrus.Entry {
+	var fields logrus.Fields
+	set := func(key string, value interface{}) {
+		if fields == nil {
+			fields = make(logrus.Fields, 3)
+		}
+		fields[key] = value
+	}
+
+	if _, ok := l.rawEntry.Data[errorKey].(error); !ok {
+		if err := firstError(args); err != nil {
+			set(argErrorKey, err)
+		}
+	}
+	if l.tree.reportCaller {
+		set(callerKey, callerFrame(l.tree.callerSkip))
+	}
+	if l.tree.stackTraceEnabled(level) {
+		stack := findStackTrace(args)
+		if err, ok := l.rawEntry.Data[errorKey].(error); ok && stack == nil {
+			stack = errorStackTrace(err)
+		}
+		if stack == nil {
+			stack = captureStackTrace(l.tree.callerSkip)
+		}
+		set(stackTraceKey, stack)
+	}
+	for key, value := range l.rawEntry.Data {
+		if lazy, ok := value.(Lazy); ok {
+			set(key, lazy())
+		}
+	}
+	if fields == nil {
+		return l.rawEntry
+	}
+	return l.rawEntry.WithFields(fields)
 }
 
 func (l *StdLogger) Trace(args ...interface{}) {
-	l.rawEntry.Trace(args...)
+	l.Log(TraceLevel, args...)
 }
 
 func (l *StdLogger) Tracef(format string, args ...interface{}) {
-	l.rawEntry.Tracef(format, args...)
+	l.Logf(TraceLevel, format, args...)
 }
 
 func (l *StdLogger) Debug(args ...interface{}) {
-	l.rawEntry.Debug(args...)
+	l.Log(DebugLevel, args...)
 }
 
 func (l *StdLogger) Debugf(format string, args ...interface{}) {
-	l.rawEntry.Debugf(format, args...)
+	l.Logf(DebugLevel, format, args...)
 }
 
 func (l *StdLogger) Info(args ...interface{}) {
-	l.rawEntry.Info(args...)
+	l.Log(InfoLevel, args...)
 }
 
 func (l *StdLogger) Infof(format string, args ...interface{}) {
-	l.rawEntry.Infof(format, args...)
+	l.Logf(InfoLevel, format, args...)
 }
 
 func (l *StdLogger) Warn(args ...interface{}) {
-	l.rawEntry.Warn(args...)
+	l.Log(WarnLevel, args...)
 }
 
 func (l *StdLogger) Warnf(format string, args ...interface{}) {
-	l.rawEntry.Warnf(format, args...)
+	l.Logf(WarnLevel, format, args...)
 }
 
 func (l *StdLogger) Error(args ...interface{}) {
-	l.rawEntry.Error(args...)
+	l.Log(ErrorLevel, args...)
 }
 
 func (l *StdLogger) Errorf(format string, args ...interface{}) {
-	l.rawEntry.Errorf(format, args...)
+	l.Logf(ErrorLevel, format, args...)
+}
+
+// Fatal logs the message and terminates the program afterwards.
+// Asynchronous hooks and outputs are flushed and closed before exiting. See LoggerBuilder.WithExitFunc.
+func (l *StdLogger) Fatal(args ...interface{}) {
+	l.Log(FatalLevel, args...)
+	l.exit()
+}
+
+// Fatalf logs the message and terminates the program afterwards.
+// Asynchronous hooks and outputs are flushed and closed before exiting. See LoggerBuilder.WithExitFunc.
+func (l *StdLogger) Fatalf(format string, args ...interface{}) {
+	l.Logf(FatalLevel, format, args...)
+	l.exit()
+}
+
+func (l *StdLogger) exit() {
+	_ = l.Close()
+	l.tree.exitFunc(1)
+}
+
+// Panic logs the message and panics with it afterwards.
+// Asynchronous hooks and outputs are flushed before panicking.
+func (l *StdLogger) Panic(args ...interface{}) {
+	args = resolveLazy(args)
+	msg := fmt.Sprint(args...)
+	if l.IsLevelEnabled(PanicLevel) {
+		l.write(PanicLevel, args, msg)
+	}
+	_ = l.Flush(context.Background())
+	panic(msg)
+}
+
+// Panicf logs the message and panics with it afterwards.
+// Asynchronous hooks and outputs are flushed before panicking.
+func (l *StdLogger) Panicf(format string, args ...interface{}) {
+	args = resolveLazy(args)
+	msg := fmt.Sprintf(format, args...)
+	if l.IsLevelEnabled(PanicLevel) {
+		l.write(PanicLevel, args, msg)
+	}
+	_ = l.Flush(context.Background())
+	panic(msg)
+}
+
+func (l *StdLogger) LogFn(level Level, fn func() string) {
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	l.log(level, &logMessage{msg: fn(), formatted: true})
+}
+
+func (l *StdLogger) TraceFn(fn func() string) {
+	l.LogFn(TraceLevel, fn)
+}
+
+func (l *StdLogger) DebugFn(fn func() string) {
+	l.LogFn(DebugLevel, fn)
+}
+
+func (l *StdLogger) InfoFn(fn func() string) {
+	l.LogFn(InfoLevel, fn)
+}
+
+func (l *StdLogger) WarnFn(fn func() string) {
+	l.LogFn(WarnLevel, fn)
+}
+
+func (l *StdLogger) ErrorFn(fn func() string) {
+	l.LogFn(ErrorLevel, fn)
+}
+
+func (l *StdLogger) LogCtx(ctx context.Context, level Level, args ...interface{}) {
+	if l.IsLevelEnabled(level) {
+		l.withFields(ContextFields(ctx), ctx).Log(level, args...)
+	}
+}
+
+func (l *StdLogger) LogfCtx(ctx context.Context, level Level, format string, args ...interface{}) {
+	if l.IsLevelEnabled(level) {
+		l.withFields(ContextFields(ctx), ctx).Logf(level, format, args...)
+	}
+}
+
+func (l *StdLogger) TraceCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, TraceLevel, args...)
+}
+
+func (l *StdLogger) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, TraceLevel, format, args...)
+}
+
+func (l *StdLogger) DebugCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, DebugLevel, args...)
+}
+
+func (l *StdLogger) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, DebugLevel, format, args...)
+}
+
+func (l *StdLogger) InfoCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, InfoLevel, args...)
+}
+
+func (l *StdLogger) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, InfoLevel, format, args...)
+}
+
+func (l *StdLogger) WarnCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, WarnLevel, args...)
+}
+
+func (l *StdLogger) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, WarnLevel, format, args...)
+}
+
+func (l *StdLogger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	l.LogCtx(ctx, ErrorLevel, args...)
+}
+
+func (l *StdLogger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	l.LogfCtx(ctx, ErrorLevel, format, args...)
+}
+
+// Flush blocks until all asynchronous hooks and outputs of the logger tree processed pending messages,
+// or the context is cancelled.
+func (l *StdLogger) Flush(ctx context.Context) error {
+	return l.tree.flush(ctx)
+}
+
+// Close flushes and closes all hooks and outputs of the logger tree that were configured via the LoggerBuilder.
+// Affects all parent- and sub-loggers. Messages logged afterwards might get lost.
+func (l *StdLogger) Close() error {
+	return l.tree.close()
+}
+
+// Reopen reopens all log files of the logger tree that were configured via LoggerBuilder.WithFileOutput.
+// Intended for external log rotation, e.g. after receiving SIGHUP from logrotate.
+// Affects all parent- and sub-loggers.
+func (l *StdLogger) Reopen() error {
+	return l.tree.reopen()
 }
 
 func (l *StdLogger) AddHook(levels []Level, hook Hook) *StdLogger {
-	adapter := newLogrusHookAdapter(levels, hook)
+	return l.AddEntryHook(levels, hook.EntryHook())
+}
+
+func (l *StdLogger) AddEntryHook(levels []Level, hook EntryHook) *StdLogger {
+	adapter := newLogrusHookAdapter(levels, l.tree.trackHook(hook))
 	l.rawLogger().AddHook(adapter)
 	return l
 }
+
+// HookStats returns invocation statistics of all hooks of the logger tree, in registration order.
+func (l *StdLogger) HookStats() []HookStats {
+	return l.tree.hookStats()
+}
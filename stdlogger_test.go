@@ -0,0 +1,271 @@
+package mlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(name ...string) (Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logger := NewLoggerBuilder(name...).
+		WithOutput(buf).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(level.String() + " " + name + " " + msg + "\n"), nil
+		}).
+		Create()
+	return logger, buf
+}
+
+// newEntryTestLogger creates a "root" logger that writes "<level> <name> <msg> <fields>" lines
+// and records every entry it passes to hooks.
+// configure, if not nil, can adjust the builder before the logger is created.
+func newEntryTestLogger(configure func(b *LoggerBuilder)) (Logger, *bytes.Buffer, *[]*Entry) {
+	buf := &bytes.Buffer{}
+	var entries []*Entry
+	builder := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithEntryFormatter(func(entry *Entry) ([]byte, error) {
+			return []byte(entry.Level.String() + " " + entry.Name + " " + entry.messageWithFields() + "\n"), nil
+		}).
+		WithEntryHook(AllLevels, func(entry *Entry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+	if configure != nil {
+		configure(builder)
+	}
+	return builder.Create(), buf, &entries
+}
+
+func TestStdLogger_WithFields(t *testing.T) {
+	logger, buf := newTestLogger("root")
+
+	logger.With("request", 42).Info("msg")
+	logger.With("request", 42).New("sub").WithFields(Fields{"user": "bob"}).Warn("msg")
+	logger.With("name", "clash").Info("msg")
+	logger.Info("msg")
+
+	assert.Equal(t, "info root msg request=42\n"+
+		"warn root.sub msg request=42 user=bob\n"+
+		"info root msg fields.name=clash\n"+
+		"info root msg\n", buf.String())
+}
+
+func TestStdLogger_EntryHook(t *testing.T) {
+	logger, _, entries := newEntryTestLogger(nil)
+
+	err := errors.New("failure")
+	logger.With("key", "value", "error", err).Warnf("msg %d", 1)
+
+	assert.Len(t, *entries, 1)
+	entry := (*entries)[0]
+	assert.Equal(t, WarnLevel, entry.Level)
+	assert.Equal(t, "root", entry.Name)
+	assert.Equal(t, "msg 1", entry.Message)
+	assert.Equal(t, Fields{"key": "value"}, entry.Fields)
+	assert.Equal(t, err, entry.Error)
+	assert.Equal(t, "msg 1 error=failure key=value", entry.messageWithFields())
+}
+
+func TestStdLogger_LevelRegistry(t *testing.T) {
+	root, buf := newTestLogger("root")
+	db := root.New("db")
+	pool := db.New("pool")
+	http := root.New("http")
+
+	logAll := func() {
+		for _, logger := range []Logger{root, db, pool, http} {
+			logger.Debug("msg")
+		}
+	}
+
+	logAll()
+	assert.Empty(t, buf.String())
+
+	registry := root.(*StdLogger).LevelRegistry()
+	registry.SetLevel("root.db", DebugLevel)
+	logAll()
+	assert.Equal(t, "debug root.db msg\ndebug root.db.pool msg\n", buf.String())
+	assert.Equal(t, DebugLevel, pool.Level())
+	assert.Equal(t, InfoLevel, http.Level())
+
+	buf.Reset()
+	registry.SetLevel("root.db.pool", ErrorLevel)
+	db.New("future").Debug("msg")
+	logAll()
+	assert.Equal(t, "debug root.db.future msg\ndebug root.db msg\n", buf.String())
+
+	buf.Reset()
+	registry.Clear()
+	pool.SetLevel(DebugLevel)
+	logAll()
+	assert.Equal(t, "debug root.db.pool msg\n", buf.String())
+}
+
+func TestStdLogger_WriterLevel(t *testing.T) {
+	logger, buf := newTestLogger("root")
+
+	w := logger.WriterLevel(WarnLevel)
+	_, _ = w.Write([]byte("line 1\nline"))
+	_, _ = w.Write([]byte(" 2\r\nline 3"))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "warn root line 1\nwarn root line 2\nwarn root line 3\n", buf.String())
+}
+
+func TestStdLogger_InheritLevel(t *testing.T) {
+	root := NewLoggerBuilder("root").WithInheritLevel().Create().(*StdLogger)
+	db := root.New("db").(*StdLogger)
+	pool := db.New("pool").With("key", "value")
+
+	assert.Equal(t, InfoLevel, pool.Level())
+
+	root.SetLevel(DebugLevel)
+	assert.Equal(t, DebugLevel, db.Level())
+	assert.Equal(t, DebugLevel, pool.Level())
+
+	db.SetLevel(WarnLevel)
+	root.SetLevel(TraceLevel)
+	assert.Equal(t, WarnLevel, db.Level())
+	assert.Equal(t, WarnLevel, pool.Level())
+
+	db.ResetLevel()
+	assert.Equal(t, TraceLevel, pool.Level())
+
+	root.ResetLevel() // no parent
+	assert.Equal(t, TraceLevel, root.Level())
+}
+
+func TestStdLogger_CopyLevel(t *testing.T) {
+	root := NewLoggerBuilder("root").Create().(*StdLogger)
+	sub := root.New("sub").(*StdLogger)
+
+	root.SetLevel(DebugLevel)
+	assert.Equal(t, InfoLevel, sub.Level())
+
+	sub.ResetLevel()
+	assert.Equal(t, InfoLevel, sub.Level())
+}
+
+func TestStdLogger_CloneLevel(t *testing.T) {
+	builder := NewLoggerBuilder("root").WithLevel(WarnLevel)
+	first := builder.Create()
+	second := builder.Create()
+	builder.WithLevel(ErrorLevel)
+	assert.Equal(t, WarnLevel, first.Level())
+	assert.Equal(t, WarnLevel, second.Level())
+
+	first.SetLevel(DebugLevel)
+	assert.Equal(t, WarnLevel, second.Level())
+
+	clone := first.(*StdLogger).Clone()
+	assert.Equal(t, DebugLevel, clone.Level())
+	clone.SetLevel(TraceLevel)
+	clone.New("sub").SetLevel(InfoLevel)
+	assert.Equal(t, DebugLevel, first.Level())
+	assert.Equal(t, DebugLevel, first.New("sub").Level())
+}
+
+func TestStdLogger_SetLevelConcurrently(t *testing.T) {
+	root := NewLoggerBuilder("root").WithInheritLevel().WithOutput(&bytes.Buffer{}).Create()
+	sub := root.New("sub")
+	fields := root.With("key", "value")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			root.SetLevel(AllLevels[i%len(AllLevels)])
+		}
+		fields.SetLevel(WarnLevel)
+	}()
+	for i := 0; i < 100; i++ {
+		sub.Debug("msg")
+	}
+	<-done
+
+	assert.Equal(t, WarnLevel, root.Level())
+	assert.Equal(t, WarnLevel, fields.Level())
+	assert.Equal(t, WarnLevel, sub.Level())
+}
+
+func TestStdLogger_WithError(t *testing.T) {
+	logger, buf, captured := newEntryTestLogger(nil)
+
+	err := fmt.Errorf("wrapped: %w", io.EOF)
+	other := errors.New("other")
+	logger.WithError(err).Warn("msg")
+	logger.Errorf("failed: %v", err)
+	logger.WithError(err).Error("failed: ", other)
+	logger.Info("msg")
+
+	entries := *captured
+	assert.Len(t, entries, 4)
+	assert.Equal(t, err, entries[0].Error)
+	assert.Equal(t, err, entries[1].Error)
+	assert.Equal(t, err, entries[2].Error)
+	assert.Nil(t, entries[3].Error)
+	assert.Empty(t, entries[1].Fields)
+
+	assert.Equal(t, "*fmt.wrapError", entries[0].ErrorType())
+	assert.Equal(t, []error{err, io.EOF}, entries[0].ErrorChain())
+
+	assert.Equal(t, "warn root msg error=wrapped: EOF\n"+
+		"error root failed: wrapped: EOF\n"+
+		"error root failed: other error=wrapped: EOF\n"+
+		"info root msg\n", buf.String())
+}
+
+func TestStdLogger_Fatal(t *testing.T) {
+	var m sync.Mutex
+	var hooked []string
+	var exitCodes []int
+
+	buf := &bytes.Buffer{}
+	logger := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(level.String() + " " + msg + "\n"), nil
+		}).
+		WithAsyncHook(AllLevels, func(entry *Entry) error {
+			time.Sleep(10 * time.Millisecond)
+			m.Lock()
+			defer m.Unlock()
+			hooked = append(hooked, entry.Message)
+			return nil
+		}, AsyncHookOptions{}).
+		WithExitFunc(func(code int) {
+			m.Lock()
+			defer m.Unlock()
+			exitCodes = append(exitCodes, code)
+			assert.Equal(t, []string{"fatal 1"}, hooked) // flushed before exiting
+		}).
+		Create()
+
+	logger.Fatalf("fatal %d", 1)
+
+	assert.Equal(t, []int{1}, exitCodes)
+	assert.Equal(t, "fatal fatal 1\n", buf.String())
+}
+
+func TestStdLogger_Panic(t *testing.T) {
+	logger, buf := newTestLogger("root")
+
+	assert.PanicsWithValue(t, "panic 1", func() {
+		logger.Panicf("panic %d", 1)
+	})
+	assert.PanicsWithValue(t, "panic", func() {
+		logger.Panic("panic")
+	})
+	assert.NotPanics(t, func() {
+		logger.Log(PanicLevel, "log")
+	})
+	assert.Equal(t, "panic root panic 1\npanic root panic\npanic root log\n", buf.String())
+}
@@ -0,0 +1,282 @@
+package mlog
+
+import (
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// loggerTree contains the state shared by a root logger and all loggers derived from it.
+type loggerTree struct {
+	levels           *LevelRegistry
+	verbosity        *VerbosityRegistry
+	samplers         map[string]*Sampler     // by logger name; not modified after the tree was built
+	rateLimiters     map[string]*RateLimiter // by logger name; not modified after the tree was built
+	dedup            *deduplicator
+	inheritLevel     bool
+	hookErrorHandler HookErrorHandler
+	reportCaller     bool
+	callerSkip       int
+	stackTraceLevels []Level
+	exitFunc         func(code int)
+
+	m           sync.Mutex
+	levelScopes map[uint64]map[string]*levelState // level states by scope id and logger name
+	nextScopeID uint64
+	closers     []io.Closer // hooks and outputs to flush and close on shutdown
+	hooks       []*hookState
+}
+
+// flusher is implemented by asynchronous hooks and outputs.
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+func newLoggerTree() *loggerTree {
+	return &loggerTree{
+		levels:      NewLevelRegistry(),
+		verbosity:   NewVerbosityRegistry(),
+		exitFunc:    os.Exit,
+		levelScopes: make(map[uint64]map[string]*levelState),
+	}
+}
+
+// levelScope groups the level states of a logger and its sub-loggers.
+// Loggers of the same scope with the same name share their level. Clones start a new scope.
+// The scope is no longer tracked once all of its loggers were garbage collected.
+type levelScope struct {
+	id   uint64
+	tree *loggerTree
+}
+
+// newLevelScope returns a new, empty level scope.
+func (t *loggerTree) newLevelScope() *levelScope {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.nextScopeID++
+	scope := &levelScope{id: t.nextScopeID, tree: t}
+	t.levelScopes[scope.id] = make(map[string]*levelState)
+	runtime.SetFinalizer(scope, func(scope *levelScope) {
+		scope.tree.m.Lock()
+		defer scope.tree.m.Unlock()
+		delete(scope.tree.levelScopes, scope.id)
+	})
+	return scope
+}
+
+// levelState returns the level state of all loggers of the scope with the given name, for runtime level control.
+// If the name is not known yet, the state returned by newState is remembered.
+// Loggers without a scope are not tracked.
+func (t *loggerTree) levelState(scope *levelScope, name string, newState func() *levelState) *levelState {
+	if scope == nil {
+		return newState()
+	}
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	states := t.levelScopes[scope.id]
+	if state, ok := states[name]; ok {
+		return state
+	}
+	state := newState()
+	states[name] = state
+	return state
+}
+
+// lookupLevelStates returns the level states of all loggers with the given name, one per scope.
+func (t *loggerTree) lookupLevelStates(name string) []*levelState {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	ids := make([]uint64, 0, len(t.levelScopes))
+	for id, states := range t.levelScopes {
+		if _, ok := states[name]; ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	states := make([]*levelState, len(ids))
+	for i, id := range ids {
+		states[i] = t.levelScopes[id][name]
+	}
+	return states
+}
+
+// loggerNames returns the names of all loggers, in no particular order.
+func (t *loggerTree) loggerNames() []string {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	unique := make(map[string]struct{})
+	for _, states := range t.levelScopes {
+		for name := range states {
+			unique[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	return names
+}
+
+// level returns the effective level of all loggers with the given name and level state.
+// Levels configured in the level registry take precedence.
+func (t *loggerTree) level(name string, state *levelState) Level {
+	if lvl, ok := t.levels.Level(name); ok {
+		return lvl
+	}
+	return state.get()
+}
+
+// addCloser registers a hook or output that needs to be flushed and closed on shutdown.
+func (t *loggerTree) addCloser(c io.Closer) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.closers = append(t.closers, c)
+}
+
+// flush flushes all registered hooks and outputs in reverse registration order.
+// Returns the first error.
+func (t *loggerTree) flush(ctx context.Context) error {
+	t.m.Lock()
+	closers := make([]io.Closer, len(t.closers))
+	copy(closers, t.closers)
+	t.m.Unlock()
+
+	var firstErr error
+	for i := len(closers) - 1; i >= 0; i-- {
+		f, ok := closers[i].(flusher)
+		if !ok {
+			continue
+		}
+		if err := f.Flush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// reopener is implemented by file outputs.
+type reopener interface {
+	Reopen() error
+}
+
+// reopen reopens all registered file outputs.
+// Returns the first error.
+func (t *loggerTree) reopen() error {
+	t.m.Lock()
+	closers := make([]io.Closer, len(t.closers))
+	copy(closers, t.closers)
+	t.m.Unlock()
+
+	var firstErr error
+	for _, c := range closers {
+		r, ok := c.(reopener)
+		if !ok {
+			continue
+		}
+		if err := r.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// close closes all registered hooks and outputs in reverse registration order.
+// Returns the first error.
+func (t *loggerTree) close() error {
+	t.m.Lock()
+	closers := t.closers
+	t.closers = nil
+	t.m.Unlock()
+
+	var firstErr error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// allow returns true if the message passes deduplication, sampling and rate limiting.
+// The message is only formatted beforehand if it is needed for deduplication or sampling.
+// Messages with PanicLevel or FatalLevel are always allowed.
+func (t *loggerTree) allow(logger *StdLogger, level Level, m *logMessage) bool {
+	if level == PanicLevel || level == FatalLevel {
+		return true
+	}
+	if t.dedup != nil && !t.dedup.allow(logger, level, m.text()) {
+		return false
+	}
+	if sampler := t.sampler(logger.name); sampler != nil && !sampler.allow(logger, level, m.template()) {
+		return false
+	}
+	if limiter := t.rateLimiter(logger.name); limiter != nil && !limiter.allow(logger, level) {
+		return false
+	}
+	return true
+}
+
+// sampler returns the sampler for the logger with the given name, or nil if messages are not sampled.
+// The sampler of the most specific name takes precedence.
+func (t *loggerTree) sampler(name string) *Sampler {
+	s, _ := lookupName(t.samplers, name)
+	return s
+}
+
+// rateLimiter returns the rate limiter for the logger with the given name, or nil if there is none.
+// The rate limiter of the most specific name takes precedence.
+func (t *loggerTree) rateLimiter(name string) *RateLimiter {
+	r, _ := lookupName(t.rateLimiters, name)
+	return r
+}
+
+// trackHook returns a hook that records invocation statistics and handles errors.
+func (t *loggerTree) trackHook(hook EntryHook) EntryHook {
+	return t.newHookState().wrap(hook)
+}
+
+// newHookState returns the statistics of a new hook.
+func (t *loggerTree) newHookState() *hookState {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	state := &hookState{
+		tree:  t,
+		index: len(t.hooks),
+	}
+	t.hooks = append(t.hooks, state)
+	return state
+}
+
+// hookStats returns the statistics of all tracked hooks.
+func (t *loggerTree) hookStats() []HookStats {
+	t.m.Lock()
+	hooks := make([]*hookState, len(t.hooks))
+	copy(hooks, t.hooks)
+	t.m.Unlock()
+
+	stats := make([]HookStats, len(hooks))
+	for i, h := range hooks {
+		stats[i] = h.stats()
+	}
+	return stats
+}
+
+// stackTraceEnabled returns true if stack traces are captured for messages with the given level.
+func (t *loggerTree) stackTraceEnabled(level Level) bool {
+	for _, lvl := range t.stackTraceLevels {
+		if lvl == level {
+			return true
+		}
+	}
+	return false
+}
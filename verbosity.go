@@ -0,0 +1,96 @@
+package mlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// VerbosityRegistry configures the verbosity of loggers based on their names, similar to klog's -v and -vmodule flags.
+// Messages of loggers returned by Logger.V(n) are only logged if the configured verbosity is at least n.
+// A verbosity registered for a name applies to the logger with that name and all of its sub-loggers,
+// with the most specific name taking precedence.
+// The empty name matches all loggers. The verbosity defaults to 0.
+type VerbosityRegistry struct {
+	m           sync.RWMutex
+	verbosities map[string]int
+}
+
+// NewVerbosityRegistry returns a new, empty verbosity registry.
+func NewVerbosityRegistry() *VerbosityRegistry {
+	return &VerbosityRegistry{
+		verbosities: make(map[string]int),
+	}
+}
+
+// SetVerbosity sets the verbosity of the logger with the given name and all its sub-loggers.
+func (r *VerbosityRegistry) SetVerbosity(name string, v int) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.verbosities[name] = v
+}
+
+// UnsetVerbosity removes the verbosity of the given name.
+// Does not remove the verbosities of more specific names.
+func (r *VerbosityRegistry) UnsetVerbosity(name string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.verbosities, name)
+}
+
+// SetVModule sets the verbosities of multiple loggers.
+// The spec is a comma-separated list of name=verbosity pairs, like "db=2,http.client=4".
+// No verbosity is changed if the spec is invalid.
+func (r *VerbosityRegistry) SetVModule(spec string) error {
+	verbosities := make(map[string]int)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		idx := strings.LastIndex(pair, "=")
+		if idx < 0 {
+			return fmt.Errorf("invalid vmodule pair %q: missing verbosity", pair)
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(pair[idx+1:]))
+		if err != nil {
+			return fmt.Errorf("invalid vmodule pair %q: %w", pair, err)
+		}
+		verbosities[strings.TrimSpace(pair[:idx])] = v
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	for name, v := range verbosities {
+		r.verbosities[name] = v
+	}
+	return nil
+}
+
+// Clear removes all verbosities.
+func (r *VerbosityRegistry) Clear() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.verbosities = make(map[string]int)
+}
+
+// Verbosities returns a copy of all registered verbosities.
+func (r *VerbosityRegistry) Verbosities() map[string]int {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	verbosities := make(map[string]int, len(r.verbosities))
+	for name, v := range r.verbosities {
+		verbosities[name] = v
+	}
+	return verbosities
+}
+
+// Verbosity returns the verbosity for the logger with the given name.
+// Returns 0 if neither the name nor any of its parents have a verbosity.
+func (r *VerbosityRegistry) Verbosity(name string) int {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	v, _ := lookupName(r.verbosities, name)
+	return v
+}
@@ -0,0 +1,88 @@
+package mlog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerbosityRegistry(t *testing.T) {
+	reg := NewVerbosityRegistry()
+	assert.Equal(t, 0, reg.Verbosity("db"))
+
+	reg.SetVerbosity("", 1)
+	reg.SetVerbosity("db", 3)
+	assert.Equal(t, 1, reg.Verbosity("http"))
+	assert.Equal(t, 3, reg.Verbosity("db.pool"))
+	assert.Equal(t, 1, reg.Verbosity("dbx"))
+
+	assert.NoError(t, reg.SetVModule("db.pool=5, http.client = 2,"))
+	assert.Equal(t, 5, reg.Verbosity("db.pool.conn"))
+	assert.Equal(t, 3, reg.Verbosity("db"))
+	assert.Equal(t, 2, reg.Verbosity("http.client"))
+
+	assert.Error(t, reg.SetVModule("db=1,http"))
+	assert.Error(t, reg.SetVModule("db=x"))
+	assert.Equal(t, 3, reg.Verbosity("db"))
+
+	reg.UnsetVerbosity("db")
+	assert.Equal(t, 1, reg.Verbosity("db"))
+	assert.Equal(t, map[string]int{"": 1, "db.pool": 5, "http.client": 2}, reg.Verbosities())
+
+	reg.Clear()
+	assert.Empty(t, reg.Verbosities())
+}
+
+func TestStdLogger_V(t *testing.T) {
+	buf := &bytes.Buffer{}
+	root := NewLoggerBuilder("root").
+		WithOutput(buf).
+		WithConsoleFormatter(func(timestamp time.Time, level Level, name string, msg string) ([]byte, error) {
+			return []byte(name + " " + msg + "\n"), nil
+		}).
+		WithVerbosity(1).
+		WithNameVerbosity("root.db", 3).
+		Create()
+	db := root.New("db")
+
+	root.V(0).Info("v0")
+	root.V(1).Info("v1")
+	root.V(2).Info("disabled")
+	root.V(1).With("k", "v").Info("v1 with fields")
+	root.V(5).New("db").Info("disabled")
+	root.V(3).New("db").Info("sub-loggers keep the verbosity")
+	db.V(3).Info("v3")
+	db.V(4).Info("disabled")
+	db.V(3).Debug("disabled by level")
+
+	assert.Equal(t, "root v0\nroot v1\nroot v1 with fields k=v\n"+
+		"root.db sub-loggers keep the verbosity\nroot.db v3\n", buf.String())
+
+	assert.False(t, db.V(4).IsLevelEnabled(ErrorLevel))
+	root.(*StdLogger).VerbosityRegistry().SetVerbosity("root.db", 4)
+	assert.True(t, db.V(4).IsLevelEnabled(ErrorLevel))
+}
+
+func TestSlogLogger_V(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo - 2,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := FromSlog(slog.New(handler))
+
+	logger.V(2).Info("v2")
+	logger.V(3).Info("disabled")
+	logger.V(2).New("sub").Info("sub v2")
+	logger.V(3).New("sub").Info("disabled")
+
+	assert.Equal(t, "level=DEBUG+2 msg=v2\nlevel=DEBUG+2 msg=\"sub v2\" name=sub\n", buf.String())
+}
@@ -0,0 +1,49 @@
+package mlog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// lineWriter splits written data into lines and passes them to a callback.
+type lineWriter struct {
+	m      sync.Mutex
+	buf    bytes.Buffer
+	onLine func(line string)
+}
+
+func newLineWriter(onLine func(line string)) io.WriteCloser {
+	return &lineWriter{
+		onLine: onLine,
+	}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.buf.Write(p)
+	for {
+		idx := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(w.buf.Next(idx + 1))
+		w.onLine(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// Close flushes any incomplete line.
+func (w *lineWriter) Close() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.onLine(w.buf.String())
+		w.buf.Reset()
+	}
+	return nil
+}